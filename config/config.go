@@ -1,9 +1,125 @@
 package config
 
+// Config is the root of a configuration file.  Fields whose names start
+// with "x-" are ignored so that they can hold, e.g., YAML anchors, and so
+// is a "$schema" field that refers editors to JSONSchema.
 type Config struct {
+	// Include are the paths or glob patterns of other configuration
+	// files, relative to this file's directory, whose definitions are
+	// merged into this configuration.
+	Include []string
+
 	Namespace      string
 	Databases      map[string]Database
 	DatabaseNamers Namers
+
+	// Go holds options specific to generated Go models.
+	Go Go
+
+	// CS holds options specific to generated C# models.
+	CS CS
+
+	// WorkView holds options specific to generated WorkView ACE
+	// workbooks.
+	WorkView WorkView
+
+	// Targets are the outputs generated from the configuration when
+	// sqlmodelgen is not given a model context.
+	Targets []Target
+}
+
+type Target struct {
+	// Context is the name of the model context that generates the
+	// target:  "cs", "csef", "go" or "wvace".
+	Context string
+
+	// TemplateDir is an optional directory of templates that
+	// override the model context's templates of the same names.
+	TemplateDir string
+
+	// Output is the file that the target is written to or, if Split
+	// is true, the directory that its files are written into.
+	// Relative paths are relative to the configuration file's
+	// directory.
+	Output string
+
+	// Split writes one file per type into the Output directory.
+	Split bool
+
+	// Namespace, if set, overrides the configuration's Namespace.
+	Namespace string
+
+	// Go, CS and WorkView, if set, override the configuration's
+	// options of the same names.
+	Go       *Go
+	CS       *CS
+	WorkView *WorkView
+}
+
+type WorkView struct {
+	// ClassPrefix selects how class names are prefixed:  "database"
+	// prefixes every class name with its database's name, "none"
+	// never prefixes them and the default prefixes them only when a
+	// workbook holds the classes of more than one database.
+	ClassPrefix string
+}
+
+type CS struct {
+	// SupportNamespace is the namespace of an existing assembly
+	// that declares the support types (IId, IKey, etc.) referenced
+	// by the generated models.  If it is empty, the support types
+	// are generated into the configuration's Namespace.
+	SupportNamespace string
+
+	// ModelKind is the kind of type generated for each model:
+	// "class" (the default), "record" or "record struct".  Record
+	// structs have no navigation properties.
+	ModelKind string
+
+	// InitOnly generates init-only property setters.
+	InitOnly bool
+
+	// Required adds the required modifier to the properties of
+	// non-nullable columns.
+	Required bool
+
+	// Nullable enables nullable reference types in the generated
+	// code so that reference-type properties of nullable columns
+	// are distinguished from those of non-nullable columns.
+	Nullable bool
+
+	// JSON selects the JSON library that the generated ID and key
+	// types' converters are written for:  "System.Text.Json" (the
+	// default), "Newtonsoft.Json" or "none".
+	JSON string
+
+	// JSONAttributes attributes the ID and key types with their JSON
+	// converters.  Otherwise, the converters must be registered with
+	// the serializer (e.g. from the generated JsonConverters class).
+	JSONAttributes bool
+}
+
+type Go struct {
+	// Tags are the struct tags added to every generated model field.
+	Tags []GoTag
+}
+
+type GoTag struct {
+	// Key is the struct tag key, e.g. "json", "db" or "yaml".
+	Key string
+
+	// Name selects which of the column's names is used as the tag
+	// value:  "RawName", "SQLName" (the default) or "ModelName".
+	Name string
+
+	// Namer, if set, is applied to the column's RawName to produce
+	// the tag value instead of using one of the column's names.
+	Namer string
+
+	// OmitEmpty adds the omitempty option to the tag values of
+	// nullable columns' fields.  It is meant for encoding keys such
+	// as "json" or "yaml" that omit nil fields.
+	OmitEmpty bool
 }
 
 type Namers struct {
@@ -28,9 +144,22 @@ type Schema struct {
 }
 
 type Table struct {
-	// PK is a comma-separated list of column names that uniquely
-	// identify records in this table.
+	// Columns are the table's columns by their names.
 	Columns map[string]Column
+
+	// WorkView holds the metadata of the table's WorkView class.
+	WorkView WorkViewTable
+}
+
+type WorkViewTable struct {
+	// Filters, Views and Sections are the names of the class's
+	// filters, views and sections that include each of its
+	// attributes unless a column overrides them.  They default to
+	// "All <class>", "<class>" and "<class>", respectively.  Names
+	// cannot contain commas.
+	Filters  []string
+	Views    []string
+	Sections []string
 }
 
 type Column struct {
@@ -43,8 +172,46 @@ type Column struct {
 	// primary key that this FK refers to.  If referencing a table
 	// in another schema, use schema.table.column or if another
 	// database, database.schema.table.column.
-	FK   string
+	FK string
+
+	// Type is the column's SQL type:  bool, int(bits), uint(bits),
+	// float(mantissa bits), decimal(scale: s, prec: p), string(length:
+	// n, var: true), bytes(length: n, var: true), date(min: t, max:
+	// t, prec: d), datetz(min: t, max: t, prec: d), duration(prec: d)
+	// or guid, optionally wrapped in nullable(...).  Parameters are
+	// optional and names are case-insensitive.
 	Type string
+
+	// Tags overrides the Go struct tags of the column's field.  Keys
+	// are the tag keys and values are the literal tag values.  An
+	// empty value omits that tag from the field.
+	Tags map[string]string
+
+	// WorkView holds the metadata of the column's WorkView
+	// attribute.
+	WorkView WorkViewColumn
+}
+
+type WorkViewColumn struct {
+	// Description of the attribute.
+	Description string
+
+	// Filters, Views and Sections, if set, override the table's
+	// WorkView Filters, Views and Sections for this attribute.  Their
+	// names cannot contain commas.
+	Filters  []string
+	Views    []string
+	Sections []string
+
+	// DataSet holds the values that the attribute is limited to.
+	// Values cannot contain commas.
+	DataSet []string
+
+	// DefaultValue of the attribute.
+	DefaultValue string
+
+	// Index of the attribute.
+	Index string
 }
 
 type View Table
@@ -0,0 +1,318 @@
+//go:build ignore
+// +build ignore
+
+// genschema generates schema.json, the JSON Schema of configuration
+// files, from the types of the config package and their doc comments.
+// Run it with go generate whenever the types change.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/skillian/sqlmodel"
+	"github.com/skillian/sqlmodel/config"
+)
+
+// schema is a JSON Schema (draft-07).
+type schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Examples             []string           `json:"examples,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	PatternProperties    map[string]*schema `json:"patternProperties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Definitions          map[string]*schema `json:"definitions,omitempty"`
+}
+
+// namerNames are the names of the namers that the configBuilder
+// resolves.
+var namerNames = sqlmodelgen.NamerNames()
+
+// fieldSchemas are the schemas of the string fields, by their
+// "Type.Field" keys, whose values are restricted.  They must be kept in
+// sync with the configBuilder that checks them.
+var fieldSchemas = map[string]*schema{
+	"Namers.SQLNamer":      {Type: "string", Enum: namerNames},
+	"Namers.ModelNamer":    {Type: "string", Enum: namerNames},
+	"GoTag.Namer":          {Type: "string", Enum: namerNames},
+	"GoTag.Name":           {Type: "string", Enum: []string{"", "RawName", "SQLName", "ModelName"}},
+	"Target.Context":       {Type: "string", Enum: sqlmodelgen.ModelContextNames()},
+	"CS.ModelKind":         {Type: "string", Enum: []string{"", "class", "record", "record struct"}},
+	"CS.JSON":              {Type: "string", Enum: []string{"", "System.Text.Json", "Newtonsoft.Json", "none"}},
+	"WorkView.ClassPrefix": {Type: "string", Enum: []string{"", "database", "none"}},
+	"Column.Type": {
+		Type:    "string",
+		Pattern: sqlTypePattern(),
+		Examples: []string{
+			"bool",
+			"int(32)",
+			"int(64)",
+			"uint(32)",
+			"float(53)",
+			"decimal(scale: 2, prec: 10)",
+			"string(length: 64)",
+			"string(length: 64, var: true)",
+			"bytes(length: 16)",
+			"bytes(var: true)",
+			"date(min: 0001-01-01, max: 9999-12-31, prec: 24h)",
+			"date(min: 1753-01-01, max: 9999-12-31, prec: 3ms)",
+			"datetz(min: 0001-01-01, max: 9999-12-31, prec: 100ns)",
+			"duration(prec: 1ms)",
+			"guid",
+			"nullable(int(32))",
+			"nullable(string(length: 64, var: true))",
+		},
+	},
+}
+
+// sqlTypePattern gets the pattern of the type strings that
+// sqlmodelgen.ParseSQLType parses.
+func sqlTypePattern() string {
+	const (
+		sp  = `\s*`
+		num = `\d+`
+		// values cannot hold commas or parentheses.
+		val = `[^,()]*`
+	)
+	params := func(keys ...string) string {
+		for i, k := range keys {
+			keys[i] = caseInsensitive(k)
+		}
+		kv := sp + `(?:` + strings.Join(keys, "|") + `)` + sp + `:` + val
+		return `(?:` + kv + `(?:,` + kv + `)*|` + sp + `)`
+	}
+	call := func(name, args string) string {
+		return caseInsensitive(name) + `\(` + args + `\)`
+	}
+	core := `(?:` + strings.Join([]string{
+		caseInsensitive("bool"),
+		caseInsensitive("guid"),
+		call("int", num),
+		call("uint", sp+num+sp),
+		call("float", num),
+		call("decimal", params("scale", "prec")),
+		call("duration", params("prec")),
+		call("datetz", params("min", "max", "prec")),
+		call("date", params("min", "max", "prec")),
+		call("string", params("length", "var")),
+		call("bytes", params("length", "var")),
+	}, "|") + `)`
+	return `^` + sp + `(?:` + core + `|` + call("nullable", sp+core+sp) + `)` + sp + `$`
+}
+
+// caseInsensitive gets a pattern that matches s case-insensitively.
+// JSON Schema patterns have no flags to do so.
+func caseInsensitive(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		u, l := unicode.ToUpper(r), unicode.ToLower(r)
+		if u == l {
+			b.WriteString(regexpQuote(r))
+			continue
+		}
+		fmt.Fprintf(&b, "[%c%c]", u, l)
+	}
+	return b.String()
+}
+
+func regexpQuote(r rune) string {
+	if strings.ContainsRune(`\.+*?()|[]{}^$`, r) {
+		return `\` + string(r)
+	}
+	return string(r)
+}
+
+// generator creates the schemas of the config types.
+type generator struct {
+	// docs are the doc comments of the config types and their fields
+	// by their "Type" and "Type.Field" keys.
+	docs map[string]string
+
+	// aliases are the types declared as other types (e.g. View) by
+	// their names.
+	aliases map[string]string
+
+	definitions map[string]*schema
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "genschema:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	g := generator{
+		docs:        make(map[string]string),
+		aliases:     make(map[string]string),
+		definitions: make(map[string]*schema),
+	}
+	if err := g.parseDocs("config.go"); err != nil {
+		return err
+	}
+	root := g.object(reflect.TypeOf(config.Config{}), "Config")
+	root.Schema = "http://json-schema.org/draft-07/schema#"
+	root.Title = "sqlmodelgen configuration"
+	root.Definitions = g.definitions
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	return ioutil.WriteFile("schema.json", buf.Bytes(), 0666)
+}
+
+// parseDocs parses the doc comments of the file's types and fields.
+func (g *generator) parseDocs(name string) error {
+	f, err := parser.ParseFile(token.NewFileSet(), name, nil, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			doc := ts.Doc
+			if doc == nil {
+				doc = gd.Doc
+			}
+			g.docs[ts.Name.Name] = docText(doc)
+			switch t := ts.Type.(type) {
+			case *ast.Ident:
+				g.aliases[ts.Name.Name] = t.Name
+			case *ast.StructType:
+				g.parseFieldDocs(ts.Name.Name, t)
+			}
+		}
+	}
+	return nil
+}
+
+func (g *generator) parseFieldDocs(prefix string, st *ast.StructType) {
+	for _, f := range st.Fields.List {
+		for _, name := range f.Names {
+			key := prefix + "." + name.Name
+			g.docs[key] = docText(f.Doc)
+			if st, ok := f.Type.(*ast.StructType); ok {
+				g.parseFieldDocs(key, st)
+			}
+		}
+	}
+}
+
+// docText gets the text of a doc comment as a single paragraph.
+func docText(cg *ast.CommentGroup) string {
+	if cg == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(cg.Text()), " ")
+}
+
+// schemaOf gets the schema of values of type t.  key is the "Type.Field"
+// key of the field that t is the type of.
+func (g *generator) schemaOf(t reflect.Type, key string) *schema {
+	if s, ok := fieldSchemas[key]; ok {
+		c := *s
+		return &c
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schemaOf(t.Elem(), key)
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.objectOf(t, key)
+		}
+		g.define(t)
+		return &schema{Ref: "#/definitions/" + t.Name()}
+	case reflect.Map:
+		return &schema{
+			Type:                 "object",
+			AdditionalProperties: g.schemaOf(t.Elem(), ""),
+		}
+	case reflect.Slice:
+		return &schema{Type: "array", Items: g.schemaOf(t.Elem(), "")}
+	case reflect.String:
+		return &schema{Type: "string"}
+	case reflect.Bool:
+		return &schema{Type: "boolean"}
+	}
+	panic(fmt.Sprintf("genschema: unsupported type: %v", t))
+}
+
+// define adds the definition of the named type, t.  Aliases are defined
+// as references to the types that they are declared as.
+func (g *generator) define(t reflect.Type) {
+	name := t.Name()
+	if _, ok := g.definitions[name]; ok {
+		return
+	}
+	alias, ok := g.aliases[name]
+	if !ok {
+		g.definitions[name] = g.object(t, name)
+		return
+	}
+	g.definitions[name] = &schema{Ref: "#/definitions/" + alias}
+	if _, ok := g.definitions[alias]; !ok {
+		// t has the same fields as the type that it is declared as.
+		g.definitions[alias] = g.object(t, alias)
+	}
+}
+
+// object gets the schema of the struct type, t, named name.
+func (g *generator) object(t reflect.Type, name string) *schema {
+	s := g.objectOf(t, name)
+	s.Description = g.docs[name]
+	return s
+}
+
+// objectOf gets the schema of the struct type, t, whose fields' doc
+// comments have the prefix.  Like the decoder, the schema matches the
+// field names case-insensitively and allows extension fields whose
+// names start with "x-" and, at the root, "$schema" fields.
+func (g *generator) objectOf(t reflect.Type, prefix string) *schema {
+	s := &schema{
+		Type:       "object",
+		Properties: make(map[string]*schema, t.NumField()),
+		PatternProperties: map[string]*schema{
+			"^x-": {},
+		},
+		AdditionalProperties: false,
+	}
+	if prefix == "Config" {
+		s.Properties["$schema"] = &schema{Type: "string"}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		key := prefix + "." + f.Name
+		fs := g.schemaOf(f.Type, key)
+		fs.Description = g.docs[key]
+		s.Properties[f.Name] = fs
+		s.PatternProperties["^"+caseInsensitive(f.Name)+"$"] = fs
+	}
+	return s
+}
@@ -0,0 +1,16 @@
+package config
+
+import _ "embed"
+
+//go:generate go run genschema.go
+
+// JSONSchema is the JSON Schema of configuration files.  Editors that
+// support JSON Schemas (e.g. VS Code, directly for JSON files and with
+// the YAML extension for YAML files) use it to complete and validate
+// configurations.
+//
+// Do not edit schema.json:  It is generated from this package's types
+// by genschema.go.
+//
+//go:embed schema.json
+var JSONSchema []byte
@@ -2,10 +2,16 @@ package sqlmodelgen
 
 import (
 	"embed"
+	"io"
 	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
 
 	"github.com/skillian/expr/errors"
 	"github.com/skillian/expr/stream/sqlstream/sqltypes"
+	"github.com/skillian/sqlmodel/config"
 )
 
 var (
@@ -13,6 +19,10 @@ var (
 	CSModelContext interface {
 		ModelContext
 		TemplateContext
+		FuncMapper
+		NamespaceEnsurer
+		NamespaceOrganizer
+		FileSplitter
 	} = csModelContext{}
 
 	//go:embed cs/*.txt
@@ -31,6 +41,159 @@ type csModelContext struct{}
 
 func (csModelContext) FS() fs.FS { return csModelFs }
 
+// FuncMap adds the C#-specific template functions.
+func (csModelContext) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"csdecimal":     csDecimalOf,
+		"csisreference": csIsReference,
+		"csparam":       csParamName,
+		"cstype":        csPropertyTypeOf,
+	}
+}
+
+func (b *configBuilder) initCS(c *config.CS) error {
+	b.Config.CSSupportNamespace = c.SupportNamespace
+	switch c.ModelKind {
+	case "":
+		b.Config.CSModelKind = "class"
+	case "class", "record", "record struct":
+		b.Config.CSModelKind = c.ModelKind
+	default:
+		return configErrorAt(errors.Errorf1(
+			"invalid C# model kind: %q", c.ModelKind,
+		), "ModelKind")
+	}
+	b.Config.CSInitOnly = c.InitOnly
+	b.Config.CSRequired = c.Required
+	b.Config.CSNullable = c.Nullable
+	switch c.JSON {
+	case "":
+		b.Config.CSJSON = "System.Text.Json"
+	case "System.Text.Json", "Newtonsoft.Json":
+		b.Config.CSJSON = c.JSON
+	case "none":
+		b.Config.CSJSON = ""
+	default:
+		return configErrorAt(errors.Errorf1(
+			"invalid C# JSON library: %q", c.JSON,
+		), "JSON")
+	}
+	b.Config.CSJSONAttributes = c.JSONAttributes
+	return nil
+}
+
+// csReferenceTypes are the C# model types that are reference types.
+var csReferenceTypes = map[string]struct{}{
+	"byte[]": {},
+	"object": {},
+	"string": {},
+}
+
+// csBaseTypeOf gets the C# type of c without any nullability.
+func csBaseTypeOf(c *Column) (string, error) {
+	switch {
+	case c.FK != nil:
+		return c.FK.ModelName, nil
+	case c.ID != nil:
+		return c.ID.ModelName, nil
+	}
+	t := c.Type
+	_ = sqltypes.IterInners(t, func(x sqltypes.Type) error {
+		t = x
+		return io.EOF
+	})
+	_, typename, err := CSModelContext.ModelType(t)
+	return typename, err
+}
+
+// csDecimalOf gets the decimal type of t or nil if t is not a decimal.
+func csDecimalOf(t sqltypes.Type) *sqltypes.DecimalType {
+	var dt *sqltypes.DecimalType
+	_ = sqltypes.IterInners(t, func(x sqltypes.Type) error {
+		if x, ok := x.(sqltypes.DecimalType); ok {
+			dt = &x
+		}
+		return io.EOF
+	})
+	return dt
+}
+
+// csIsReference checks if c's property is of a reference type.
+func csIsReference(c *Column) (bool, error) {
+	typename, err := csBaseTypeOf(c)
+	if err != nil {
+		return false, err
+	}
+	if c.FK != nil || c.ID != nil {
+		return false, nil
+	}
+	_, ok := csReferenceTypes[typename]
+	return ok, nil
+}
+
+// csPropertyTypeOf gets the type of c's property.  Nullable reference
+// types are only marked as nullable if nullable reference types are
+// enabled.
+func csPropertyTypeOf(c *Column) (string, error) {
+	typename, err := csBaseTypeOf(c)
+	if err != nil {
+		return "", err
+	}
+	if !sqltypes.IsNullable(c.Type) {
+		return typename, nil
+	}
+	isRef, err := csIsReference(c)
+	if err != nil {
+		return "", err
+	}
+	if isRef && !c.Table.Schema.Database.Config.CSNullable {
+		return typename, nil
+	}
+	return typename + "?", nil
+}
+
+// EnsureNamespaces includes the namespace of the support types if they
+// are declared in an existing assembly.
+func (csModelContext) EnsureNamespaces(c *Config) []string {
+	return []string{c.CSSupportNamespace}
+}
+
+// csKeywords are the C# keywords that must be escaped with '@' to be used
+// as identifiers.
+var csKeywords = func() map[string]struct{} {
+	kws := strings.Fields(`
+		abstract as base bool break byte case catch char checked class
+		const continue decimal default delegate do double else enum
+		event explicit extern false finally fixed float for foreach
+		goto if implicit in int interface internal is lock long
+		namespace new null object operator out override params
+		private protected public readonly ref return sbyte sealed
+		short sizeof stackalloc static string struct switch this
+		throw true try typeof uint ulong unchecked unsafe ushort
+		using virtual void volatile while`)
+	m := make(map[string]struct{}, len(kws))
+	for _, kw := range kws {
+		m[kw] = struct{}{}
+	}
+	return m
+}()
+
+// csParamName creates a camelCase parameter name from a PascalCase model
+// name.
+func csParamName(name string) string {
+	if name == "" {
+		return name
+	}
+	name = strings.ToLower(name[:1]) + name[1:]
+	if _, ok := csKeywords[name]; ok {
+		return "@" + name
+	}
+	return name
+}
+
+// csMaxDecimalPrec is the most digits that a C# decimal can hold.
+const csMaxDecimalPrec = 28
+
 func (csModelContext) ModelType(t sqltypes.Type) (namespace, typename string, err error) {
 	switch t := t.(type) {
 	case sqltypes.BoolType:
@@ -38,7 +201,7 @@ func (csModelContext) ModelType(t sqltypes.Type) (namespace, typename string, er
 	case sqltypes.IntType:
 		switch {
 		case t.Bits <= 8:
-			return "", "byte", nil
+			return "", "sbyte", nil
 		case t.Bits <= 16:
 			return "", "short", nil
 		case t.Bits <= 32:
@@ -49,6 +212,20 @@ func (csModelContext) ModelType(t sqltypes.Type) (namespace, typename string, er
 		return "", "", errors.Errorf1(
 			"int with %d bits not supported",
 			t.Bits)
+	case UintType:
+		switch {
+		case t.Bits <= 8:
+			return "", "byte", nil
+		case t.Bits <= 16:
+			return "", "ushort", nil
+		case t.Bits <= 32:
+			return "", "uint", nil
+		case t.Bits <= 64:
+			return "", "ulong", nil
+		}
+		return "", "", errors.Errorf1(
+			"uint with %d bits not supported",
+			t.Bits)
 	case sqltypes.FloatType:
 		switch {
 		case t.Mantissa <= 24:
@@ -59,15 +236,110 @@ func (csModelContext) ModelType(t sqltypes.Type) (namespace, typename string, er
 		return "", "", errors.Errorf1(
 			"float with %d mantissa bits not "+
 				"supported", t.Mantissa)
+	case sqltypes.DecimalType:
+		if t.Prec > csMaxDecimalPrec || t.Scale > csMaxDecimalPrec {
+			return "", "", errors.Errorf3(
+				"decimal with scale %d and precision %d "+
+					"not supported: C# decimals have at "+
+					"most %d digits", t.Scale, t.Prec,
+				csMaxDecimalPrec)
+		}
+		return "", "decimal", nil
 	case sqltypes.Nullable:
 		ns, tn, err := CSModelContext.ModelType(t[0])
 		return ns, tn + "?", err
 	case sqltypes.StringType:
 		return "", "string", nil
 	case sqltypes.TimeType:
-		return "time", "DateTime", nil
+		return "System", "DateTime", nil
+	case ZonedTimeType:
+		return "System", "DateTimeOffset", nil
+	case DurationType:
+		return "System", "TimeSpan", nil
+	case GUIDType:
+		return "System", "Guid", nil
 	case sqltypes.BytesType:
 		return "", "byte[]", nil
 	}
-	return "", "object", nil
+	return "", "", errors.Errorf1(
+		"no C# mapping for %[1]v (type: %[1]T)", t)
+}
+
+// csImplicitNamespaces are the namespaces that the root templates
+// always import.
+var csImplicitNamespaces = map[string]struct{}{
+	"System":                     {},
+	"System.Collections.Generic": {},
+	"System.Linq":                {},
+}
+
+// OrganizeNamespaces sorts the namespaces and removes those that the
+// root templates already import.
+func (csModelContext) OrganizeNamespaces(ns []string) []string {
+	res := ns[:0]
+	for _, n := range ns {
+		if _, ok := csImplicitNamespaces[n]; !ok {
+			res = append(res, n)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// SplitFiles writes each ID, key and model type into its own file in a
+// directory named after its namespace relative to the configuration's
+// namespace.
+func (csModelContext) SplitFiles(c *Config) ([]OutputFile, error) {
+	ofs := make([]OutputFile, 0, 64)
+	add := func(dir, name, ns, tmpl string, data interface{}) {
+		ofs = append(ofs, OutputFile{
+			Path:     path.Join(dir, name+".cs"),
+			Template: "file.txt",
+			Data: Dict{
+				"Config":    c,
+				"Namespace": ns,
+				"Template":  tmpl,
+				"Data":      data,
+			},
+		})
+	}
+	if c.CSSupportNamespace == "" {
+		add("", "Support", "", "support.txt", c)
+	}
+	for _, db := range c.Databases {
+		if c.CSJSON != "" {
+			add(db.ModelName, db.ModelName+"JsonConverters", "", "jsonconverters.txt", db)
+		}
+		for _, sch := range db.Schemas {
+			dir := path.Join(db.ModelName, sch.ModelName)
+			ns := csNamespaceOf(sch)
+			for _, tbl := range sch.Tables {
+				if tbl.PK != nil {
+					add(dir, tbl.PK.ModelName, ns, "id.txt", tbl.PK)
+				} else if tbl.Key != nil {
+					for _, id := range tbl.Key.IDs {
+						if id.Column.FK == nil {
+							add(dir, id.ModelName, ns, "id.txt", id)
+						}
+					}
+					add(dir, tbl.Key.ModelName, ns, "key.txt", tbl.Key)
+				}
+				add(dir, tbl.ModelName, ns, "model.txt", tbl)
+			}
+		}
+	}
+	return ofs, nil
+}
+
+// csNamespaceOf gets the namespace of the schema's models the same way
+// as the namespace.txt template.
+func csNamespaceOf(s *Schema) string {
+	ns := s.Database.Config.Namespace
+	if s.Database.ModelName != "" {
+		ns += "." + s.Database.ModelName
+	}
+	if s.ModelName != "" {
+		ns += "." + s.ModelName
+	}
+	return ns
 }
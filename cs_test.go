@@ -0,0 +1,36 @@
+package sqlmodelgen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCSDecimalPrecision(t *testing.T) {
+	tests := []struct {
+		typ     string
+		wantErr bool
+	}{
+		{"decimal()", false},
+		{"decimal(scale: 2, prec: 28)", false},
+		{"decimal(scale: 28, prec: 28)", false},
+		{"decimal(scale: 2, prec: 29)", true},
+		{"decimal(scale: 0, prec: 38)", true},
+		{"decimal(scale: 29)", true},
+		{"nullable(decimal(scale: 4, prec: 38))", true},
+	}
+	for _, tc := range tests {
+		st, err := ParseSQLType(tc.typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, typename, err := CSModelContext.ModelType(st)
+		switch {
+		case tc.wantErr && err == nil:
+			t.Errorf("%s was mapped to %s", tc.typ, typename)
+		case tc.wantErr && !strings.Contains(firstLine(err), "at most 28 digits"):
+			t.Errorf("%s: unexpected error: %v", tc.typ, err)
+		case !tc.wantErr && err != nil:
+			t.Errorf("%s: %v", tc.typ, err)
+		}
+	}
+}
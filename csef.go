@@ -0,0 +1,57 @@
+package sqlmodelgen
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+)
+
+var (
+	// CSEFCoreModelContext generates Entity Framework Core DbContexts
+	// for the models generated by CSModelContext.
+	CSEFCoreModelContext interface {
+		ModelContext
+		TemplateContext
+		FuncMapper
+		NamespaceEnsurer
+		NamespaceOrganizer
+		FileSplitter
+	} = csEFCoreModelContext{}
+
+	//go:embed csef/*.txt
+	csEFCoreFs embed.FS
+
+	// csEFCoreModelFs overlays the EF Core templates on top of the C#
+	// templates so that the C# naming templates can be reused.
+	csEFCoreModelFs fs.FS = func() fs.FS {
+		fsys, err := fs.Sub(csEFCoreFs, "csef")
+		if err != nil {
+			panic(err)
+		}
+		return OverlayFS(fsys, csModelFs)
+	}()
+)
+
+// csEFCoreModelContext shares csModelContext's data types and template
+// functions.
+type csEFCoreModelContext struct{ csModelContext }
+
+func (csEFCoreModelContext) FS() fs.FS { return csEFCoreModelFs }
+
+// SplitFiles writes each database's DbContext into its own file.
+func (csEFCoreModelContext) SplitFiles(c *Config) ([]OutputFile, error) {
+	ofs := make([]OutputFile, 0, len(c.Databases))
+	for _, db := range c.Databases {
+		ofs = append(ofs, OutputFile{
+			Path:     path.Join(db.ModelName, db.ModelName+"DbContext.cs"),
+			Template: "file.txt",
+			Data: Dict{
+				"Config":    c,
+				"Namespace": c.Namespace,
+				"Template":  "database.txt",
+				"Data":      db,
+			},
+		})
+	}
+	return ofs, nil
+}
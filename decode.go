@@ -0,0 +1,373 @@
+package sqlmodelgen
+
+import (
+	goerrors "errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/sqlmodel/config"
+)
+
+// ConfigPos is a position within a configuration file.
+type ConfigPos struct {
+	File string
+
+	// Line and Column are 1-based.  They are zero if they are
+	// unknown (e.g. within WorkView ACE workbooks).
+	Line   int
+	Column int
+}
+
+func (p ConfigPos) String() string {
+	s := p.File
+	if p.Line == 0 {
+		return s
+	}
+	if s != "" {
+		s += ":"
+	}
+	s += strconv.Itoa(p.Line)
+	if p.Column == 0 {
+		return s
+	}
+	return s + ":" + strconv.Itoa(p.Column)
+}
+
+// ConfigError is an error in the value of a configuration at Path.
+type ConfigError struct {
+	// Path holds the field names, map keys and slice indexes from
+	// the configuration's root to the value.
+	Path []string
+
+	Err error
+}
+
+func (e *ConfigError) Error() string { return e.Err.Error() }
+
+func (e *ConfigError) Unwrap() error { return e.Err }
+
+// configErrorAt wraps err into a *ConfigError at the path or, if err
+// already wraps a *ConfigError, prefixes its Path with path.
+func configErrorAt(err error, path ...string) error {
+	var ce *ConfigError
+	if goerrors.As(err, &ce) {
+		ce.Path = append(append([]string(nil), path...), ce.Path...)
+		return err
+	}
+	return &ConfigError{Path: path, Err: err}
+}
+
+// configPositions are the positions of configuration values by their
+// pathKeys.
+type configPositions map[string]ConfigPos
+
+// pathKey gets the key of a path within configPositions.
+func pathKey(path ...string) string { return strings.Join(path, "\x00") }
+
+// lookup gets the position of the value at the path or else of its
+// nearest ancestor.
+func (ps configPositions) lookup(path ...string) (ConfigPos, bool) {
+	for i := len(path); i >= 0; i-- {
+		if pos, ok := ps[pathKey(path[:i]...)]; ok {
+			return pos, true
+		}
+	}
+	return ConfigPos{}, false
+}
+
+type configNodeKind uint8
+
+const (
+	nullNode configNodeKind = iota
+	boolNode
+	numberNode
+	stringNode
+	arrayNode
+	objectNode
+)
+
+func (k configNodeKind) String() string {
+	return [...]string{
+		"null", "a boolean", "a number", "a string", "an array",
+		"an object",
+	}[k]
+}
+
+// configNode is a value parsed from a configuration file, before it is
+// decoded into a config.Config.
+type configNode struct {
+	kind configNodeKind
+	pos  ConfigPos
+
+	// value is the bool of a boolNode or the text of a numberNode or
+	// stringNode.
+	value interface{}
+
+	items  []*configNode
+	fields []configField
+}
+
+// configField is a member of an objectNode.
+type configField struct {
+	key   string
+	pos   ConfigPos
+	value *configNode
+}
+
+// decodeConfig strictly decodes the configuration from its parsed root
+// node:  Unknown fields, values of the wrong types and duplicate keys
+// are errors.  Extension fields, whose names start with "x-", are
+// ignored so that they can hold, e.g., YAML anchors, and so is the root's
+// "$schema" field that refers editors to the JSON Schema.  The
+// positions of the decoded values are also returned.
+func decodeConfig(n *configNode) (*config.Config, configPositions, error) {
+	d := configDecoder{positions: configPositions{"": n.pos}}
+	var c config.Config
+	if err := d.decode(n, reflect.ValueOf(&c).Elem(), nil); err != nil {
+		return nil, nil, err
+	}
+	return &c, d.positions, nil
+}
+
+type configDecoder struct {
+	positions configPositions
+}
+
+func (d *configDecoder) decode(n *configNode, v reflect.Value, path []string) error {
+	if n.kind == nullNode {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decode(n, v.Elem(), path)
+	case reflect.Struct:
+		if n.kind != objectNode {
+			return typeError(n, "an object", path)
+		}
+		return d.decodeStruct(n, v, path)
+	case reflect.Map:
+		if n.kind != objectNode {
+			return typeError(n, "an object", path)
+		}
+		return d.decodeMap(n, v, path)
+	case reflect.Slice:
+		if n.kind != arrayNode {
+			return typeError(n, "an array", path)
+		}
+		s := reflect.MakeSlice(v.Type(), len(n.items), len(n.items))
+		for i, item := range n.items {
+			ipath := appendPath(path, strconv.Itoa(i))
+			d.positions[pathKey(ipath...)] = item.pos
+			if err := d.decode(item, s.Index(i), ipath); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	case reflect.String:
+		if n.kind != stringNode {
+			return typeError(n, "a string", path)
+		}
+		v.SetString(n.value.(string))
+		return nil
+	case reflect.Bool:
+		if n.kind != boolNode {
+			return typeError(n, "a boolean", path)
+		}
+		v.SetBool(n.value.(bool))
+		return nil
+	}
+	return errors.Errorf2(
+		"%v: cannot decode configuration into %v", n.pos, v.Type(),
+	)
+}
+
+func (d *configDecoder) decodeStruct(n *configNode, v reflect.Value, path []string) error {
+	t := v.Type()
+	set := make(map[int]ConfigPos, len(n.fields))
+	for _, f := range n.fields {
+		if strings.HasPrefix(f.key, "x-") || f.key == "$schema" && len(path) == 0 {
+			continue
+		}
+		i := structField(t, f.key)
+		if i == -1 {
+			return unknownFieldError(f, t, path)
+		}
+		if pos, ok := set[i]; ok {
+			return errors.Errorf3(
+				"%v: %s is already set at %v",
+				f.pos, t.Field(i).Name, pos,
+			)
+		}
+		set[i] = f.pos
+		fpath := appendPath(path, t.Field(i).Name)
+		d.positions[pathKey(fpath...)] = f.pos
+		if err := d.decode(f.value, v.Field(i), fpath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *configDecoder) decodeMap(n *configNode, v reflect.Value, path []string) error {
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(v.Type(), len(n.fields)))
+	}
+	set := make(map[string]ConfigPos, len(n.fields))
+	for _, f := range n.fields {
+		if pos, ok := set[f.key]; ok {
+			return errors.Errorf3(
+				"%v: %q is already defined at %v",
+				f.pos, f.key, pos,
+			)
+		}
+		set[f.key] = f.pos
+		fpath := appendPath(path, f.key)
+		d.positions[pathKey(fpath...)] = f.pos
+		e := reflect.New(v.Type().Elem()).Elem()
+		if err := d.decode(f.value, e, fpath); err != nil {
+			return err
+		}
+		v.SetMapIndex(reflect.ValueOf(f.key), e)
+	}
+	return nil
+}
+
+// appendPath appends name to a copy of path.
+func appendPath(path []string, name string) []string {
+	return append(path[:len(path):len(path)], name)
+}
+
+// structField gets the index of the exported field of t named name.
+// Like encoding/json, names are matched case-insensitively if there is
+// no exact match.
+func structField(t reflect.Type, name string) int {
+	folded := -1
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		if f.Name == name {
+			return i
+		}
+		if folded == -1 && strings.EqualFold(f.Name, name) {
+			folded = i
+		}
+	}
+	return folded
+}
+
+func typeError(n *configNode, want string, path []string) error {
+	name := "the configuration"
+	if len(path) > 0 {
+		name = path[len(path)-1]
+	}
+	return errors.ErrorfFrom(
+		errors.Errorf2("expected %s, found %s", want, n.kind),
+		"%v: invalid %s", n.pos, name,
+	)
+}
+
+func unknownFieldError(f configField, t reflect.Type, path []string) error {
+	name := t.Name()
+	if name == "" && len(path) > 0 {
+		name = path[len(path)-1]
+	}
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath == "" {
+			names = append(names, t.Field(i).Name)
+		}
+	}
+	if s := suggest(f.key, names); s != "" {
+		return errors.ErrorfFrom(
+			errors.Errorf1("did you mean %q?", s),
+			"%v: unknown field %q of %s", f.pos, f.key, name,
+		)
+	}
+	return errors.ErrorfFrom(
+		errors.Errorf1("fields: %s", strings.Join(names, ", ")),
+		"%v: unknown field %q of %s", f.pos, f.key, name,
+	)
+}
+
+// suggest gets the candidate that name is most likely a misspelling or
+// abbreviation of or "" if there are none.
+func suggest(name string, candidates []string) string {
+	lower := strings.ToLower(name)
+	best, bestDist := "", len(lower)/3+1
+	for _, c := range candidates {
+		if dist := editDistance(lower, strings.ToLower(c)); dist < bestDist {
+			best, bestDist = c, dist
+		}
+	}
+	if best != "" {
+		return best
+	}
+	// candidates such as "PK" and "FK" are abbreviations of longer
+	// names such as "primarykey" and "foreignkey".
+	for _, c := range candidates {
+		if len(c) > 1 && len(c) < len(name) && isAbbreviation(strings.ToLower(c), lower) {
+			return c
+		}
+	}
+	return ""
+}
+
+// editDistance gets the number of insertions, deletions, substitutions
+// and transpositions of adjacent characters that turn a into b.
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	// d[i][j] is the distance between ar[:i] and br[:j].
+	d := make([][]int, len(ar)+1)
+	for i := range d {
+		d[i] = make([]int, len(br)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ar)][len(br)]
+}
+
+func minInt(x int, ys ...int) int {
+	for _, y := range ys {
+		if y < x {
+			x = y
+		}
+	}
+	return x
+}
+
+// isAbbreviation checks if abbr's characters appear in order in s,
+// starting with s's first character.
+func isAbbreviation(abbr, s string) bool {
+	if abbr == "" || s == "" || abbr[0] != s[0] {
+		return false
+	}
+	i := 0
+	for j := 0; j < len(s) && i < len(abbr); j++ {
+		if s[j] == abbr[i] {
+			i++
+		}
+	}
+	return i == len(abbr)
+}
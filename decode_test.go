@@ -0,0 +1,105 @@
+package sqlmodelgen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeConfigErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+
+		// want is the error's first line and hint is a part of the
+		// rest of its message, if set.
+		want, hint string
+	}{
+		{
+			"misspelled.json",
+			"{\n\t\"Namspace\": \"x\"\n}",
+			`misspelled.json:2:2: unknown field "Namspace" of Config`,
+			`did you mean "Namespace"?`,
+		},
+		{
+			"abbreviated.json",
+			`{"Databases": {"DB": {"Schemas": {"dbo": {"Tables": {"T": {"Columns": {"A": {"primarykey": true}}}}}}}}}`,
+			`abbreviated.json:1:78: unknown field "primarykey" of Column`,
+			`did you mean "PK"?`,
+		},
+		{
+			"unknown.json",
+			`{"Bogus": 1}`,
+			`unknown.json:1:2: unknown field "Bogus" of Config`,
+			"fields: Include, Namespace, ",
+		},
+		{
+			"type.json",
+			"{\"Databases\": {\"DB\": {\"Schemas\": {\"dbo\": {\"Tables\": {\"T\": {\"Columns\": {\"A\": {\n\"PK\": \"yes\"}}}}}}}}}",
+			"type.json:2:7: invalid PK",
+			"expected a boolean, found a string",
+		},
+		{
+			"duplicate.json",
+			"{\"Databases\": {\n\"A\": {},\n\"A\": {}}}",
+			`duplicate.json:3:1: "A" is already defined at duplicate.json:2:1`,
+			"",
+		},
+		{
+			"folded.json",
+			"{\"namespace\": \"x\",\n\"NAMESPACE\": \"y\"}",
+			"folded.json:2:1: Namespace is already set at folded.json:1:2",
+			"",
+		},
+		{
+			"extension.json",
+			`{"$schema": "s", "x-note": 1, "Databases": {"DB": {"$schema": "s"}}}`,
+			`extension.json:1:52: unknown field "$schema" of Database`,
+			"",
+		},
+		{
+			"misspelled.yaml",
+			"Databases:\n  DB:\n    Schemas:\n      dbo:\n        Tables:\n          T:\n            Columns:\n              A:\n                fks: T.B\n",
+			`misspelled.yaml:9:17: unknown field "fks" of Column`,
+			`did you mean "FK"?`,
+		},
+		{
+			"type.yaml",
+			"Databases:\n  DB:\n    Schemas: [1]\n",
+			"type.yaml:3:14: invalid Schemas",
+			"expected an object, found an array",
+		},
+		{
+			"misspelled.toml",
+			"[Databases.DB.Schemas.dbo.Tables.T.Columns.A]\nTpye = \"int(32)\"\n",
+			`misspelled.toml:2:1: unknown field "Tpye" of Column`,
+			`did you mean "Type"?`,
+		},
+		{
+			"type.toml",
+			"Namespace = 1\n",
+			// TOML values have the positions of their keys.
+			"type.toml:1:1: invalid Namespace",
+			"expected a string, found a number",
+		},
+	}
+	parsers := map[string]func(string, []byte) (*configNode, error){
+		".json": parseJSONConfig,
+		".toml": parseTOMLConfig,
+		".yaml": parseYAMLConfig,
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			parse := parsers[tc.name[strings.LastIndexByte(tc.name, '.'):]]
+			_, _, err := readConfig(tc.name, strings.NewReader(tc.data), parse)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if got := firstLine(err); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+			if !strings.Contains(err.Error(), tc.hint) {
+				t.Errorf("%q does not contain %q", err.Error(), tc.hint)
+			}
+		})
+	}
+}
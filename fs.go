@@ -0,0 +1,104 @@
+package sqlmodelgen
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// OverlayFS creates a file system from layers of file systems.  Files are
+// opened from the first layer that has them and directory listings are
+// merged so that files in earlier layers hide files with the same name in
+// later layers.
+func OverlayFS(layers ...fs.FS) fs.FS {
+	return overlayFS(layers)
+}
+
+type overlayFS []fs.FS
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	var firstErr error
+	for _, fsys := range o {
+		f, err := fsys.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if firstErr == nil || !errors.Is(err, fs.ErrNotExist) {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		firstErr = &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return nil, firstErr
+}
+
+func (o overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	seen := make(map[string]struct{})
+	var des []fs.DirEntry
+	found := false
+	for _, fsys := range o {
+		layer, err := fs.ReadDir(fsys, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		for _, de := range layer {
+			if _, ok := seen[de.Name()]; ok {
+				continue
+			}
+			seen[de.Name()] = struct{}{}
+			des = append(des, de)
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	sort.Slice(des, func(i, j int) bool { return des[i].Name() < des[j].Name() })
+	return des, nil
+}
+
+// PrefixFS creates a file system that holds fsys's files in the
+// directory, prefix.  It is the inverse of fs.Sub and lets an OverlayFS
+// keep the files of a layer that it hides reachable under another
+// directory.
+func PrefixFS(prefix string, fsys fs.FS) fs.FS {
+	return prefixFS{prefix: prefix, fsys: fsys}
+}
+
+type prefixFS struct {
+	prefix string
+	fsys   fs.FS
+}
+
+// inner gets the name of the file in p.fsys or false if name is not in
+// the prefix directory.
+func (p prefixFS) inner(name string) (string, bool) {
+	if name == p.prefix {
+		return ".", true
+	}
+	if strings.HasPrefix(name, p.prefix+"/") {
+		return name[len(p.prefix)+1:], true
+	}
+	return "", false
+}
+
+func (p prefixFS) Open(name string) (fs.File, error) {
+	inner, ok := p.inner(name)
+	if !ok || !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return p.fsys.Open(inner)
+}
+
+func (p prefixFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	inner, ok := p.inner(name)
+	if !ok || !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return fs.ReadDir(p.fsys, inner)
+}
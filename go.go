@@ -1,13 +1,25 @@
 package sqlmodelgen
 
 import (
+	"bytes"
 	"embed"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"io"
 	"io/fs"
+	"path"
 	"sort"
+	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/skillian/expr/errors"
 	"github.com/skillian/expr/stream/sqlstream/sqltypes"
+	"github.com/skillian/sqlmodel/config"
 )
 
 var (
@@ -16,6 +28,9 @@ var (
 	GoModelContext interface {
 		ModelContext
 		TemplateContext
+		FuncMapper
+		OutputFormatter
+		FileSplitter
 	} = goModelContext{}
 
 	//go:embed go/*.txt
@@ -64,30 +79,351 @@ func (goModelContext) ModelType(t sqltypes.Type) (namespace, typename string, er
 		return "", "", errors.Errorf1(
 			"float with %d mantissa bits not "+
 				"supported", t.Mantissa)
-	case sqltypes.StringType:
+	case UintType:
+		switch {
+		case t.Bits <= 8:
+			return "", "uint8", nil
+		case t.Bits <= 16:
+			return "", "uint16", nil
+		case t.Bits <= 32:
+			return "", "uint32", nil
+		case t.Bits <= 64:
+			return "", "uint64", nil
+		}
+		return "", "", errors.Errorf1(
+			"uint with %d bits not supported",
+			t.Bits)
+	case sqltypes.StringType, GUIDType:
 		return "", "string", nil
-	case sqltypes.TimeType:
+	case sqltypes.TimeType, ZonedTimeType:
 		return "time", "time.Time", nil
+	case DurationType:
+		return "time", "time.Duration", nil
 	case sqltypes.BytesType:
 		return "", "[]byte", nil
 	}
 	return "", "interface{}", nil
 }
 
-func (goModelContext) EnsureNamespaces(c *Config) []string {
-	nss := make([]string, 1, 2)
-	nss[0] = "github.com/skillian/expr/stream/sqlstream/sqltypes"
-dbLoop:
+// FuncMap adds the Go-specific template functions.
+func (goModelContext) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"gocolumntype":   goColumnTypeOf,
+		"gocolumntypes":  goColumnTypesOf,
+		"gosqltype":      goSQLTypeOf,
+		"gosqltypesexpr": goSQLTypesExpr,
+		"gotags":         goTagsOf,
+	}
+}
+
+// goSQLType describes how a Go model type is exchanged with database/sql.
+type goSQLType struct {
+	// Null is the database/sql Null* type that can scan the type.
+	Null string
+
+	// Field is the name of the Null type's field holding the value.
+	Field string
+
+	// Driver is the database/sql/driver.Value type that the model
+	// type is converted to when sent to the database.
+	Driver string
+
+	// Bits is the size of integer model types so that scanned values
+	// that they cannot hold are rejected.  It is 0 for other types.
+	Bits int
+
+	// Unsigned is true if the model type is an unsigned integer.
+	// Unsigned values are scanned from their text so that values
+	// above math.MaxInt64 are not wrapped.
+	Unsigned bool
+}
+
+// goSQLTypeOf gets the goSQLType of the inner type of t.
+func goSQLTypeOf(t sqltypes.Type) (st goSQLType, err error) {
+	_ = sqltypes.IterInners(t, func(x sqltypes.Type) error {
+		t = x
+		return io.EOF
+	})
+	switch t := t.(type) {
+	case sqltypes.BoolType:
+		return goSQLType{Null: "sql.NullBool", Field: "Bool", Driver: "bool"}, nil
+	case sqltypes.IntType:
+		return goSQLType{
+			Null: "sql.NullInt64", Field: "Int64", Driver: "int64",
+			Bits: goIntBits(t.Bits),
+		}, nil
+	case UintType:
+		return goSQLType{
+			Null: "sql.NullString", Field: "String", Driver: "int64",
+			Bits: goIntBits(t.Bits), Unsigned: true,
+		}, nil
+	case DurationType:
+		return goSQLType{
+			Null: "sql.NullInt64", Field: "Int64", Driver: "int64",
+			Bits: 64,
+		}, nil
+	case sqltypes.FloatType:
+		return goSQLType{Null: "sql.NullFloat64", Field: "Float64", Driver: "float64"}, nil
+	case sqltypes.StringType, GUIDType:
+		return goSQLType{Null: "sql.NullString", Field: "String", Driver: "string"}, nil
+	case sqltypes.TimeType, ZonedTimeType:
+		return goSQLType{Null: "sql.NullTime", Field: "Time", Driver: "time.Time"}, nil
+	case sqltypes.BytesType:
+		return goSQLType{Null: "sql.NullString", Field: "String", Driver: "[]byte"}, nil
+	default:
+		return st, errors.Errorf1(
+			"no database/sql mapping for %[1]v (type: %[1]T)",
+			t,
+		)
+	}
+}
+
+// goIntBits gets the size of the Go integer type that ModelType maps
+// integers of the given bits to.
+func goIntBits(bits int) int {
+	for _, n := range []int{8, 16, 32} {
+		if bits <= n {
+			return n
+		}
+	}
+	return 64
+}
+
+// goSQLTypesExpr formats t as a Go expression of the sqltypes package
+// so that types only known to the generator are written as the closest
+// type that sqlstream understands.
+func goSQLTypesExpr(t sqltypes.Type) string {
+	return fmt.Sprintf("%#v", coreSQLType(t))
+}
+
+// goColumnType is a column descriptor type generated for columns of a
+// Go type that isn't an ID.
+type goColumnType struct {
+	// Name of the generated column descriptor type
+	Name string
+
+	// GoType is the Go type of the column's non-NULL values.
+	GoType string
+
+	// Nullable is true if the column's values can be NULL.  The Eq
+	// and Ne predicates of nullable columns accept *GoType values so
+	// that columns can be compared with NULL.
+	Nullable bool
+}
+
+// goColumnTypeOf gets the name of the column descriptor type of c.
+func goColumnTypeOf(c *Column) (string, error) {
+	switch {
+	case c.FK != nil:
+		return c.FK.ModelName + "Column", nil
+	case c.ID != nil:
+		return c.ID.ModelName + "Column", nil
+	}
+	ct, err := goDataColumnTypeOf(c.Type)
+	if err != nil {
+		return "", err
+	}
+	return ct.Name, nil
+}
+
+// goDataColumnTypeOf gets the column descriptor type of data columns of
+// type t.
+func goDataColumnTypeOf(t sqltypes.Type) (ct goColumnType, err error) {
+	ct.Nullable = sqltypes.IsNullable(t)
+	_ = sqltypes.IterInners(t, func(x sqltypes.Type) error {
+		t = x
+		return io.EOF
+	})
+	if _, ct.GoType, err = GoModelContext.ModelType(t); err != nil {
+		return
+	}
+	ct.Name = goColumnTypeName(ct.GoType)
+	if ct.Nullable {
+		ct.Name = "Null" + ct.Name
+	}
+	return
+}
+
+// goColumnTypeName creates the name of the column descriptor type of
+// columns whose values are of the given Go type.
+func goColumnTypeName(typename string) string {
+	switch typename {
+	case "[]byte":
+		return "BytesColumn"
+	case "interface{}":
+		return "InterfaceColumn"
+	}
+	if i := strings.LastIndexByte(typename, '.'); i != -1 {
+		typename = typename[i+1:]
+	}
+	return strings.ToUpper(typename[:1]) + typename[1:] + "Column"
+}
+
+// goColumnTypesOf gets the column descriptor types of every data column
+// in the configuration, sorted by name.
+func goColumnTypesOf(c *Config) ([]goColumnType, error) {
+	m := make(map[string]goColumnType)
 	for _, db := range c.Databases {
 		for _, sch := range db.Schemas {
 			for _, tbl := range sch.Tables {
-				if tbl.PK != nil || tbl.Key != nil {
-					nss = append(nss, "github.com/skillian/expr/stream/sqlstream")
-					break dbLoop
+				for _, col := range tbl.DataColumns {
+					ct, err := goDataColumnTypeOf(col.Type)
+					if err != nil {
+						return nil, err
+					}
+					m[ct.Name] = ct
 				}
 			}
 		}
 	}
+	cts := make([]goColumnType, 0, len(m))
+	for _, ct := range m {
+		cts = append(cts, ct)
+	}
+	sort.Slice(cts, func(i, j int) bool { return cts[i].Name < cts[j].Name })
+	return cts, nil
+}
+
+// GoTag defines a struct tag added to the fields of generated Go models.
+type GoTag struct {
+	// Key is the struct tag key (e.g. "json")
+	Key string
+
+	// Name gets the tag value from a column's Names.
+	Name func(ns *Names) string
+
+	// OmitEmpty adds the omitempty option to the tags of nullable
+	// columns.
+	OmitEmpty bool
+}
+
+func (b *configBuilder) initGoTags(cs []config.GoTag) error {
+	b.Config.GoTags = make([]*GoTag, len(cs))
+	for i := range cs {
+		c := &cs[i]
+		if c.Key == "" {
+			return configErrorAt(errors.Errorf1(
+				"Go tag #%d has no key", i,
+			), strconv.Itoa(i))
+		}
+		t := &GoTag{Key: c.Key, OmitEmpty: c.OmitEmpty}
+		if c.Namer != "" {
+			nr, err := namerFromName(c.Namer)
+			if err != nil {
+				return configErrorAt(errors.Errorf2From(
+					err, "failed to initialize namer %q "+
+						"of Go tag %q",
+					c.Namer, c.Key,
+				), strconv.Itoa(i), "Namer")
+			}
+			t.Name = func(ns *Names) string { return nr.Apply(ns.RawName) }
+		} else {
+			switch c.Name {
+			case "RawName":
+				t.Name = func(ns *Names) string { return ns.RawName }
+			case "", "SQLName":
+				t.Name = func(ns *Names) string { return ns.SQLName }
+			case "ModelName":
+				t.Name = func(ns *Names) string { return ns.ModelName }
+			default:
+				return configErrorAt(errors.Errorf2(
+					"invalid name %q of Go tag %q", c.Name, c.Key,
+				), strconv.Itoa(i), "Name")
+			}
+		}
+		b.Config.GoTags[i] = t
+	}
+	return nil
+}
+
+// goTagsOf creates the struct tag of c's field, including the leading
+// space, or an empty string if the field has no tags.  The OmitEmpty
+// tags of nullable columns get the omitempty option.
+func goTagsOf(c *Column) string {
+	tags := c.Table.Schema.Database.Config.GoTags
+	parts := make([]string, 0, len(tags)+len(c.Tags))
+	appendTag := func(key, value string) {
+		parts = append(parts, key+":"+strconv.Quote(value))
+	}
+	for _, t := range tags {
+		if v, ok := c.Tags[t.Key]; ok {
+			if v != "" {
+				appendTag(t.Key, v)
+			}
+			continue
+		}
+		v := t.Name(&c.Names)
+		if t.OmitEmpty && sqltypes.IsNullable(c.Type) {
+			v += ",omitempty"
+		}
+		appendTag(t.Key, v)
+	}
+	overrides := make([]string, 0, len(c.Tags))
+	for k, v := range c.Tags {
+		if v == "" || c.hasGoTag(k) {
+			continue
+		}
+		overrides = append(overrides, k)
+	}
+	sort.Strings(overrides)
+	for _, k := range overrides {
+		appendTag(k, c.Tags[k])
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " `" + strings.Join(parts, " ") + "`"
+}
+
+// hasGoTag checks if key is one of the configuration's GoTags.
+func (c *Column) hasGoTag(key string) bool {
+	for _, t := range c.Table.Schema.Database.Config.GoTags {
+		if t.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (goModelContext) EnsureNamespaces(c *Config) []string {
+	nss := make([]string, 2, 9)
+	nss[0] = "github.com/skillian/expr/stream/sqlstream/sqlmodels"
+	nss[1] = "github.com/skillian/expr/stream/sqlstream/sqltypes"
+	hasIDs, hasFKs := false, false
+	for _, db := range c.Databases {
+		for _, sch := range db.Schemas {
+			for _, tbl := range sch.Tables {
+				hasIDs = hasIDs || tbl.PK != nil || tbl.Key != nil
+				hasFKs = hasFKs || len(tbl.FKs) > 0
+			}
+		}
+	}
+	if hasIDs || hasFKs {
+		nss = append(nss, "github.com/skillian/expr/stream/sqlstream")
+	}
+	// column descriptors and predicates are always generated
+	nss = append(
+		nss,
+		"github.com/skillian/expr",
+		"github.com/skillian/expr/stream",
+	)
+	if hasIDs {
+		// ID and key types implement database/sql and JSON
+		// interfaces.  The imports that they do not use are pruned
+		// by FormatOutput.
+		nss = append(
+			nss,
+			"database/sql",
+			"database/sql/driver",
+			"encoding/json",
+			"fmt",
+			"math",
+			"strconv",
+		)
+	}
+	if hasFKs {
+		nss = append(nss, "context")
+	}
 	return nss
 }
 
@@ -120,3 +456,136 @@ func (goModelContext) OrganizeNamespaces(nss []string) []string {
 	}
 	return append(nss, external...)
 }
+
+// FormatOutput runs the generated source through gofmt.  If the source
+// cannot be parsed, the error includes the offending line(s) of the
+// generated source so the template bug can be found.
+func (goModelContext) FormatOutput(src []byte) ([]byte, error) {
+	out, err := format.Source(src)
+	if err == nil {
+		return goPruneImports(out)
+	}
+	el, ok := err.(scanner.ErrorList)
+	if !ok || len(el) == 0 {
+		return nil, errors.Errorf0From(
+			err, "failed to format generated Go source",
+		)
+	}
+	return nil, errors.Errorf2From(
+		err, "generated Go source is invalid at line %d:\n\n%s",
+		el[0].Pos.Line, goSourceContext(src, el[0].Pos.Line, 3),
+	)
+}
+
+// goSourceContext returns the lines of src surrounding line (1-based),
+// numbered and with the line itself marked.
+func goSourceContext(src []byte, line, radius int) string {
+	lines := strings.Split(string(src), "\n")
+	first, last := line-radius, line+radius
+	if first < 1 {
+		first = 1
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+	sb := strings.Builder{}
+	for i := first; i <= last; i++ {
+		marker := "  "
+		if i == line {
+			marker = "> "
+		}
+		fmt.Fprintf(&sb, "%s%5d | %s\n", marker, i, lines[i-1])
+	}
+	return sb.String()
+}
+
+// goPruneImports removes the imports that the formatted Go source, src,
+// does not use.  Every file of split output imports all of the
+// configuration's namespaces but only uses some of them.
+func goPruneImports(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, errors.Errorf0From(
+			err, "failed to parse generated Go source",
+		)
+	}
+	used := make(map[string]struct{}, len(f.Imports))
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok && id.Obj == nil {
+				used[id.Name] = struct{}{}
+			}
+		}
+		return true
+	})
+	pruned := make(map[int]struct{}, len(f.Imports))
+	for _, is := range f.Imports {
+		name := path.Base(strings.Trim(is.Path.Value, "\"`"))
+		if is.Name != nil {
+			name = is.Name.Name
+		}
+		if _, ok := used[name]; ok || name == "_" || name == "." {
+			continue
+		}
+		pruned[fset.Position(is.Pos()).Line] = struct{}{}
+	}
+	if len(pruned) == 0 {
+		return src, nil
+	}
+	lines := bytes.SplitAfter(src, []byte("\n"))
+	out := make([]byte, 0, len(src))
+	for i, line := range lines {
+		if _, ok := pruned[i+1]; !ok {
+			out = append(out, line...)
+		}
+	}
+	return format.Source(out)
+}
+
+// SplitFiles writes the shared definitions into models.go and each
+// table's definitions into a file named after its lowercased model with
+// a _model.go suffix (e.g. party_model.go).
+func (goModelContext) SplitFiles(c *Config) ([]OutputFile, error) {
+	ofs := make([]OutputFile, 0, 64)
+	// owners are the models generated into each file by its path so
+	// that models whose file names only differ in case are reported.
+	owners := make(map[string]string, 64)
+	add := func(name, owner, tmpl string, data interface{}) error {
+		p := name + ".go"
+		if other, ok := owners[p]; ok {
+			return errors.Errorf3(
+				"%s and %s would both be generated into %s",
+				other, owner, p)
+		}
+		owners[p] = owner
+		ofs = append(ofs, OutputFile{
+			Path:     p,
+			Template: "file.txt",
+			Data: Dict{
+				"Config":   c,
+				"Template": tmpl,
+				"Data":     data,
+			},
+		})
+		return nil
+	}
+	if err := add("models", "the shared declarations", "shared.txt", c); err != nil {
+		return nil, err
+	}
+	for _, db := range c.Databases {
+		for _, sch := range db.Schemas {
+			for _, tbl := range sch.Tables {
+				// The suffix keeps the names of tables' files from
+				// colliding with models.go or ending with suffixes
+				// that the go tool treats specially, like _test or
+				// _windows.
+				name := strings.ToLower(tbl.ModelName) + "_model"
+				if err := add(name, "model "+tbl.ModelName, "table.txt", tbl); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return ofs, nil
+}
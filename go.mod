@@ -3,9 +3,15 @@ module github.com/skillian/sqlmodel
 go 1.16
 
 require (
+	github.com/BurntSushi/toml v1.3.2
+	github.com/DATA-DOG/go-sqlmock v1.5.2
 	github.com/davecgh/go-spew v1.1.1
+	github.com/denisenkom/go-mssqldb v0.12.3
+	github.com/go-sql-driver/mysql v1.6.0
+	github.com/lib/pq v1.10.9
 	github.com/skillian/argparse v0.0.0-20210419122530-5f0ba3e38218
 	github.com/skillian/expr v0.0.0-20210801124931-4933989d588e
 	github.com/skillian/logging v0.0.0-20210425124543-4b3b9b919a80
 	github.com/xuri/excelize/v2 v2.4.1
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,268 @@
+package sqlmodelgen
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/sqlmodel/config"
+)
+
+// Introspect reads the tables, columns, primary keys and foreign keys of
+// an existing database from its INFORMATION_SCHEMA views into a
+// configuration with a single database named database.  If any schemas
+// are given, only their tables are included.
+func Introspect(ctx context.Context, db *sql.DB, database string, schemas ...string) (*config.Config, error) {
+	in := introspector{
+		db:      db,
+		schemas: make(map[string]struct{}, len(schemas)),
+		config: config.Database{
+			Schemas: make(map[string]config.Schema),
+		},
+	}
+	for _, sch := range schemas {
+		in.schemas[strings.ToLower(sch)] = struct{}{}
+	}
+	if err := in.columns(ctx); err != nil {
+		return nil, errors.Errorf1From(
+			err, "failed to introspect the columns of %v",
+			database,
+		)
+	}
+	if err := in.primaryKeys(ctx); err != nil {
+		return nil, errors.Errorf1From(
+			err, "failed to introspect the primary keys of %v",
+			database,
+		)
+	}
+	if err := in.foreignKeys(ctx); err != nil {
+		return nil, errors.Errorf1From(
+			err, "failed to introspect the foreign keys of %v",
+			database,
+		)
+	}
+	return &config.Config{
+		Databases: map[string]config.Database{database: in.config},
+	}, nil
+}
+
+type introspector struct {
+	db      *sql.DB
+	schemas map[string]struct{}
+	config  config.Database
+}
+
+// includes checks if the schema's tables are introspected.
+func (in *introspector) includes(schema string) bool {
+	if len(in.schemas) == 0 {
+		return true
+	}
+	_, ok := in.schemas[strings.ToLower(schema)]
+	return ok
+}
+
+// column gets the introspected column or false if its table was not
+// introspected.
+func (in *introspector) column(schema, table, column string) (config.Column, bool) {
+	col, ok := in.config.Schemas[schema].Tables[table].Columns[column]
+	return col, ok
+}
+
+func (in *introspector) setColumn(schema, table, column string, col config.Column) {
+	sch, ok := in.config.Schemas[schema]
+	if !ok {
+		sch.Tables = make(map[string]config.Table)
+		in.config.Schemas[schema] = sch
+	}
+	tbl, ok := sch.Tables[table]
+	if !ok {
+		tbl.Columns = make(map[string]config.Column)
+		sch.Tables[table] = tbl
+	}
+	tbl.Columns[column] = col
+}
+
+func (in *introspector) columns(ctx context.Context) error {
+	rows, err := in.db.QueryContext(ctx, `
+SELECT c.TABLE_SCHEMA, c.TABLE_NAME, c.COLUMN_NAME, c.DATA_TYPE,
+	c.IS_NULLABLE, c.CHARACTER_MAXIMUM_LENGTH, c.NUMERIC_PRECISION,
+	c.NUMERIC_SCALE
+FROM INFORMATION_SCHEMA.COLUMNS c
+INNER JOIN INFORMATION_SCHEMA.TABLES t
+	ON t.TABLE_SCHEMA = c.TABLE_SCHEMA
+	AND t.TABLE_NAME = c.TABLE_NAME
+WHERE t.TABLE_TYPE = 'BASE TABLE'`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var schema, table, column, dataType, isNullable string
+		var length, prec, scale sql.NullInt64
+		if err = rows.Scan(
+			&schema, &table, &column, &dataType, &isNullable,
+			&length, &prec, &scale,
+		); err != nil {
+			return err
+		}
+		if !in.includes(schema) {
+			continue
+		}
+		typ, err := introspectSQLType(dataType, length, prec, scale)
+		if err != nil {
+			return errors.Errorf3From(
+				err, "column %s.%s.%s has an unsupported type",
+				schema, table, column,
+			)
+		}
+		if strings.EqualFold(isNullable, "YES") {
+			typ = "nullable(" + typ + ")"
+		}
+		in.setColumn(schema, table, column, config.Column{Type: typ})
+	}
+	return rows.Err()
+}
+
+func (in *introspector) primaryKeys(ctx context.Context) error {
+	rows, err := in.db.QueryContext(ctx, `
+SELECT k.TABLE_SCHEMA, k.TABLE_NAME, k.COLUMN_NAME
+FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+INNER JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE k
+	ON k.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA
+	AND k.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+	AND k.TABLE_SCHEMA = tc.TABLE_SCHEMA
+	AND k.TABLE_NAME = tc.TABLE_NAME
+WHERE tc.CONSTRAINT_TYPE = 'PRIMARY KEY'`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var schema, table, column string
+		if err = rows.Scan(&schema, &table, &column); err != nil {
+			return err
+		}
+		col, ok := in.column(schema, table, column)
+		if !ok {
+			continue
+		}
+		col.PK = true
+		in.setColumn(schema, table, column, col)
+	}
+	return rows.Err()
+}
+
+func (in *introspector) foreignKeys(ctx context.Context) error {
+	rows, err := in.db.QueryContext(ctx, `
+SELECT k.TABLE_SCHEMA, k.TABLE_NAME, k.COLUMN_NAME,
+	pk.TABLE_SCHEMA, pk.TABLE_NAME, pk.COLUMN_NAME
+FROM INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS rc
+INNER JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE k
+	ON k.CONSTRAINT_SCHEMA = rc.CONSTRAINT_SCHEMA
+	AND k.CONSTRAINT_NAME = rc.CONSTRAINT_NAME
+INNER JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE pk
+	ON pk.CONSTRAINT_SCHEMA = rc.UNIQUE_CONSTRAINT_SCHEMA
+	AND pk.CONSTRAINT_NAME = rc.UNIQUE_CONSTRAINT_NAME
+	AND pk.ORDINAL_POSITION = k.ORDINAL_POSITION`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var schema, table, column, pkSchema, pkTable, pkColumn string
+		if err = rows.Scan(
+			&schema, &table, &column,
+			&pkSchema, &pkTable, &pkColumn,
+		); err != nil {
+			return err
+		}
+		col, ok := in.column(schema, table, column)
+		if !ok {
+			continue
+		}
+		if _, ok = in.column(pkSchema, pkTable, pkColumn); !ok {
+			logger.Warn3(
+				"skipping foreign key %s.%s.%s to a table that "+
+					"is not introspected",
+				schema, table, column,
+			)
+			continue
+		}
+		if pkSchema == schema {
+			col.FK = pkTable + "." + pkColumn
+		} else {
+			col.FK = pkSchema + "." + pkTable + "." + pkColumn
+		}
+		in.setColumn(schema, table, column, col)
+	}
+	return rows.Err()
+}
+
+// introspectSQLType translates an INFORMATION_SCHEMA DATA_TYPE into the
+// type string that ParseSQLType parses.
+func introspectSQLType(dataType string, length, prec, scale sql.NullInt64) (string, error) {
+	// variable and "max" lengths are reported as -1 or very large
+	// values.
+	lengthArg := ""
+	if length.Valid && length.Int64 > 0 && length.Int64 < 1<<30 {
+		lengthArg = fmt.Sprintf("length: %d, ", length.Int64)
+	}
+	switch strings.ToLower(dataType) {
+	case "bit", "bool", "boolean":
+		return "bool", nil
+	case "tinyint":
+		return "int(8)", nil
+	case "smallint", "int2":
+		return "int(16)", nil
+	case "int", "integer", "int4", "mediumint":
+		return "int(32)", nil
+	case "bigint", "int8":
+		return "int(64)", nil
+	case "real", "float4":
+		return "float(24)", nil
+	case "float", "double", "double precision", "float8":
+		return "float(53)", nil
+	case "decimal", "numeric":
+		if !prec.Valid {
+			return "decimal()", nil
+		}
+		return fmt.Sprintf(
+			"decimal(scale: %d, prec: %d)", scale.Int64, prec.Int64,
+		), nil
+	case "money":
+		return "decimal(scale: 4, prec: 19)", nil
+	case "smallmoney":
+		return "decimal(scale: 4, prec: 10)", nil
+	case "char", "nchar", "character":
+		return "string(" + lengthArg + "var: false)", nil
+	case "varchar", "nvarchar", "character varying", "text",
+		"ntext", "tinytext", "mediumtext", "longtext", "clob":
+		return "string(" + lengthArg + "var: true)", nil
+	case "binary":
+		return "bytes(" + lengthArg + "var: false)", nil
+	case "varbinary", "image", "blob", "tinyblob", "mediumblob",
+		"longblob", "bytea":
+		return "bytes(" + lengthArg + "var: true)", nil
+	case "date":
+		return "date(prec: 24h)", nil
+	case "smalldatetime":
+		return "date(prec: 1m)", nil
+	case "datetime":
+		return "date(prec: 1ms)", nil
+	case "datetime2":
+		return "date(prec: 100ns)", nil
+	case "timestamp", "timestamp without time zone":
+		return "date(prec: 1us)", nil
+	case "datetimeoffset":
+		return "datetz(prec: 100ns)", nil
+	case "timestamptz", "timestamp with time zone":
+		return "datetz(prec: 1us)", nil
+	case "time", "time without time zone", "interval":
+		return "duration(prec: 1us)", nil
+	case "uniqueidentifier", "uuid":
+		return "guid", nil
+	}
+	return "", errors.Errorf1("unknown SQL data type: %q", dataType)
+}
@@ -0,0 +1,137 @@
+package sqlmodelgen
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/skillian/sqlmodel/config"
+)
+
+func TestIntrospectSQLType(t *testing.T) {
+	null := sql.NullInt64{}
+	n := func(i int64) sql.NullInt64 { return sql.NullInt64{Int64: i, Valid: true} }
+	tests := []struct {
+		dataType            string
+		length, prec, scale sql.NullInt64
+		want                string
+	}{
+		{"BIT", null, null, null, "bool"},
+		{"int", null, n(10), n(0), "int(32)"},
+		{"int8", null, n(64), n(0), "int(64)"},
+		{"decimal", null, n(10), n(2), "decimal(scale: 2, prec: 10)"},
+		{"numeric", null, null, null, "decimal()"},
+		{"money", null, n(19), n(4), "decimal(scale: 4, prec: 19)"},
+		{"nchar", n(10), null, null, "string(length: 10, var: false)"},
+		{"nvarchar", n(-1), null, null, "string(var: true)"},
+		{"character varying", n(64), null, null, "string(length: 64, var: true)"},
+		{"longtext", n(4294967295), null, null, "string(var: true)"},
+		{"varbinary", n(16), null, null, "bytes(length: 16, var: true)"},
+		{"datetime2", null, null, null, "date(prec: 100ns)"},
+		{"timestamp with time zone", null, null, null, "datetz(prec: 1us)"},
+		{"uniqueidentifier", n(36), null, null, "guid"},
+	}
+	for _, tc := range tests {
+		got, err := introspectSQLType(tc.dataType, tc.length, tc.prec, tc.scale)
+		if err != nil {
+			t.Errorf("%s: %v", tc.dataType, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.dataType, got, tc.want)
+		}
+		// the types must be parseable.
+		if _, err := ParseSQLType(got); err != nil {
+			t.Errorf("%s: %q does not parse: %v", tc.dataType, got, err)
+		}
+	}
+	if _, err := introspectSQLType("geography", null, null, null); err == nil {
+		t.Error("expected an error for an unknown data type")
+	}
+}
+
+func TestIntrospect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	mock.ExpectQuery(`FROM INFORMATION_SCHEMA\.COLUMNS`).WillReturnRows(
+		sqlmock.NewRows([]string{
+			"TABLE_SCHEMA", "TABLE_NAME", "COLUMN_NAME", "DATA_TYPE",
+			"IS_NULLABLE", "CHARACTER_MAXIMUM_LENGTH",
+			"NUMERIC_PRECISION", "NUMERIC_SCALE",
+		}).
+			AddRow("dbo", "Party", "PartyID", "int", "NO", nil, 10, 0).
+			AddRow("dbo", "Party", "Name", "nvarchar", "YES", 64, nil, nil).
+			AddRow("dbo", "Docket", "DocketID", "char", "NO", 12, nil, nil).
+			AddRow("dbo", "Docket", "PartyID", "int", "YES", nil, 10, 0).
+			AddRow("audit", "Log", "LogID", "bigint", "NO", nil, 19, 0).
+			AddRow("audit", "Log", "PartyID", "int", "NO", nil, 10, 0),
+	)
+	mock.ExpectQuery(`WHERE tc\.CONSTRAINT_TYPE = 'PRIMARY KEY'`).WillReturnRows(
+		sqlmock.NewRows([]string{"TABLE_SCHEMA", "TABLE_NAME", "COLUMN_NAME"}).
+			AddRow("dbo", "Party", "PartyID").
+			AddRow("dbo", "Docket", "DocketID").
+			AddRow("audit", "Log", "LogID"),
+	)
+	mock.ExpectQuery(`FROM INFORMATION_SCHEMA\.REFERENTIAL_CONSTRAINTS`).WillReturnRows(
+		sqlmock.NewRows([]string{
+			"TABLE_SCHEMA", "TABLE_NAME", "COLUMN_NAME",
+			"TABLE_SCHEMA", "TABLE_NAME", "COLUMN_NAME",
+		}).
+			AddRow("dbo", "Docket", "PartyID", "dbo", "Party", "PartyID").
+			AddRow("audit", "Log", "PartyID", "dbo", "Party", "PartyID"),
+	)
+	got, err := Introspect(context.Background(), db, "Court", "DBO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	want := &config.Config{
+		Databases: map[string]config.Database{
+			"Court": {Schemas: map[string]config.Schema{
+				"dbo": {Tables: map[string]config.Table{
+					"Party": {Columns: map[string]config.Column{
+						"PartyID": {PK: true, Type: "int(32)"},
+						"Name":    {Type: "nullable(string(length: 64, var: true))"},
+					}},
+					"Docket": {Columns: map[string]config.Column{
+						"DocketID": {PK: true, Type: "string(length: 12, var: false)"},
+						"PartyID":  {FK: "Party.PartyID", Type: "nullable(int(32))"},
+					}},
+				}},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestIntrospectUnsupportedType(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	mock.ExpectQuery(`FROM INFORMATION_SCHEMA\.COLUMNS`).WillReturnRows(
+		sqlmock.NewRows([]string{
+			"TABLE_SCHEMA", "TABLE_NAME", "COLUMN_NAME", "DATA_TYPE",
+			"IS_NULLABLE", "CHARACTER_MAXIMUM_LENGTH",
+			"NUMERIC_PRECISION", "NUMERIC_SCALE",
+		}).AddRow("dbo", "Place", "Shape", "geography", "NO", nil, nil, nil),
+	)
+	_, err = Introspect(context.Background(), db, "Court")
+	if err == nil {
+		t.Fatal("expected an error for an unsupported type")
+	}
+	const want = "failed to introspect the columns of Court"
+	if got := firstLine(err); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
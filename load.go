@@ -0,0 +1,330 @@
+package sqlmodelgen
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/sqlmodel/config"
+)
+
+// ConfigFiles are the files that a configuration was loaded from and
+// the positions of its values within them.
+type ConfigFiles struct {
+	// Names are the names of the files that were read.
+	Names []string
+
+	// Includes are the include paths and glob patterns that the
+	// files were searched for with.
+	Includes []string
+
+	positions configPositions
+}
+
+// Position gets the position of the configuration value at the path (see
+// ConfigError) or else of its nearest ancestor.
+func (fs ConfigFiles) Position(path ...string) (ConfigPos, bool) {
+	return fs.positions.lookup(path...)
+}
+
+// LoadConfig reads the configuration file, name, and merges the files
+// that it includes into it.  Files are read by their extensions:
+// ".xlsx" files as WorkView ACE workbooks, ".yaml" and ".yml" files as
+// YAML, ".toml" files as TOML and other files as JSON.  The files that
+// were read are returned even if loading fails.
+func LoadConfig(name string) (*config.Config, ConfigFiles, error) {
+	l := configLoader{
+		dir:    filepath.Dir(name),
+		files:  ConfigFiles{positions: make(configPositions)},
+		loaded: make(map[string]struct{}),
+		defs:   make(map[string]ConfigPos),
+	}
+	if err := l.load(name); err != nil {
+		return nil, l.files, err
+	}
+	return &l.config, l.files, nil
+}
+
+// configLoader merges configuration files into a single configuration.
+type configLoader struct {
+	// dir is the root configuration file's directory that the
+	// Targets' relative paths are relative to.
+	dir string
+
+	config config.Config
+
+	files ConfigFiles
+
+	// loaded holds the absolute paths of the files that were read so
+	// that each file is only merged once.
+	loaded map[string]struct{}
+
+	// defs are the positions of the definitions of each setting,
+	// database namer, table and view.
+	defs map[string]ConfigPos
+}
+
+func (l *configLoader) load(name string) error {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return errors.Errorf1From(
+			err, "failed to get absolute path of %v", name,
+		)
+	}
+	if _, ok := l.loaded[abs]; ok {
+		return nil
+	}
+	l.loaded[abs] = struct{}{}
+	l.files.Names = append(l.files.Names, name)
+	c, ps, err := readConfigFile(name)
+	if err != nil {
+		return err
+	}
+	includes := c.Include
+	c.Include = nil
+	dir := filepath.Dir(name)
+	if err = l.merge(dir, c, ps); err != nil {
+		return err
+	}
+	for i, include := range includes {
+		pos, _ := ps.lookup("Include", strconv.Itoa(i))
+		pattern := filepath.FromSlash(include)
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+		l.files.Includes = append(l.files.Includes, pattern)
+		names, err := filepath.Glob(pattern)
+		if err != nil {
+			return errors.Errorf2From(
+				err, "%v: invalid include %q", pos, include,
+			)
+		}
+		if len(names) == 0 {
+			return errors.Errorf2(
+				"%v: include %q matches no files", pos, include,
+			)
+		}
+		for _, inc := range names {
+			if err = l.load(inc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readConfigFile reads a single configuration file without its
+// includes.
+func readConfigFile(name string) (Cfg *config.Config, Ps configPositions, Err error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, nil, errors.Errorf1From(
+			err, "failed to open config file %q", name,
+		)
+	}
+	defer errors.Catch(&Err, f.Close)
+	ext := filepath.Ext(name)
+	if strings.EqualFold(ext, ".xlsx") {
+		c, err := ReadWVAceConfig(
+			f, strings.TrimSuffix(filepath.Base(name), ext), "",
+		)
+		if err != nil {
+			return nil, nil, errors.Errorf1From(
+				err, "failed to read file %v as a WorkView "+
+					"ACE workbook",
+				name,
+			)
+		}
+		return c, configPositions{"": {File: name}}, nil
+	}
+	parse := parseJSONConfig
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		parse = parseYAMLConfig
+	case ".toml":
+		parse = parseTOMLConfig
+	}
+	return readConfig(name, f, parse)
+}
+
+// readConfig reads and strictly decodes a configuration with the parse
+// function.
+func readConfig(name string, r io.Reader, parse func(name string, data []byte) (*configNode, error)) (*config.Config, configPositions, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, errors.Errorf1From(
+			err, "failed to read config file %q", name,
+		)
+	}
+	n, err := parse(name, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return decodeConfig(n)
+}
+
+// ReadJSONConfig strictly reads a configuration from JSON:  Unknown
+// fields are errors.
+func ReadJSONConfig(r io.Reader) (*config.Config, error) {
+	c, _, err := readConfig("", r, parseJSONConfig)
+	return c, err
+}
+
+// ReadYAMLConfig strictly reads a configuration from YAML.  Its keys are
+// the same as those of JSON configurations.
+func ReadYAMLConfig(r io.Reader) (*config.Config, error) {
+	c, _, err := readConfig("", r, parseYAMLConfig)
+	return c, err
+}
+
+// ReadTOMLConfig strictly reads a configuration from TOML.  Its keys are
+// the same as those of JSON configurations.
+func ReadTOMLConfig(r io.Reader) (*config.Config, error) {
+	c, _, err := readConfig("", r, parseTOMLConfig)
+	return c, err
+}
+
+// merge merges the configuration, c, read from a file in the directory,
+// dir, with the positions, ps.
+func (l *configLoader) merge(dir string, c *config.Config, ps configPositions) error {
+	settings := []struct {
+		key      string
+		dst, src interface{}
+	}{
+		{"Namespace", &l.config.Namespace, &c.Namespace},
+		{"DatabaseNamers", &l.config.DatabaseNamers, &c.DatabaseNamers},
+		{"Go", &l.config.Go, &c.Go},
+		{"CS", &l.config.CS, &c.CS},
+		{"WorkView", &l.config.WorkView, &c.WorkView},
+	}
+	for _, s := range settings {
+		pos, _ := ps.lookup(s.key)
+		if err := l.mergeSetting(s.key, pos, s.dst, s.src); err != nil {
+			return err
+		}
+	}
+	l.mergePositions(ps, len(l.config.Targets))
+	for _, t := range c.Targets {
+		t.TemplateDir = l.rebase(dir, t.TemplateDir)
+		t.Output = l.rebase(dir, t.Output)
+		l.config.Targets = append(l.config.Targets, t)
+	}
+	if len(c.Databases) > 0 && l.config.Databases == nil {
+		l.config.Databases = make(map[string]config.Database, len(c.Databases))
+	}
+	for _, dbName := range sortedKeys(c.Databases) {
+		db := c.Databases[dbName]
+		ddb := l.config.Databases[dbName]
+		pos, _ := ps.lookup("Databases", dbName, "Namers")
+		if err := l.mergeSetting(
+			"database "+dbName+" Namers", pos, &ddb.Namers, &db.Namers,
+		); err != nil {
+			return err
+		}
+		if len(db.Schemas) > 0 && ddb.Schemas == nil {
+			ddb.Schemas = make(map[string]config.Schema, len(db.Schemas))
+		}
+		for _, schName := range sortedKeys(db.Schemas) {
+			sch := db.Schemas[schName]
+			dsch := ddb.Schemas[schName]
+			path := dbName + "." + schName + "."
+			if len(sch.Tables) > 0 && dsch.Tables == nil {
+				dsch.Tables = make(map[string]config.Table, len(sch.Tables))
+			}
+			for _, tblName := range sortedKeys(sch.Tables) {
+				pos, _ := ps.lookup(
+					"Databases", dbName, "Schemas", schName,
+					"Tables", tblName,
+				)
+				if err := l.define("table "+path+tblName, pos); err != nil {
+					return err
+				}
+				dsch.Tables[tblName] = sch.Tables[tblName]
+			}
+			if len(sch.Views) > 0 && dsch.Views == nil {
+				dsch.Views = make(map[string]config.View, len(sch.Views))
+			}
+			for _, vwName := range sortedKeys(sch.Views) {
+				pos, _ := ps.lookup(
+					"Databases", dbName, "Schemas", schName,
+					"Views", vwName,
+				)
+				if err := l.define("view "+path+vwName, pos); err != nil {
+					return err
+				}
+				dsch.Views[vwName] = sch.Views[vwName]
+			}
+			ddb.Schemas[schName] = dsch
+		}
+		l.config.Databases[dbName] = ddb
+	}
+	return nil
+}
+
+// mergeSetting sets dst to src if src is set.  dst and src must be
+// pointers to the same type.
+func (l *configLoader) mergeSetting(key string, pos ConfigPos, dst, src interface{}) error {
+	sv := reflect.ValueOf(src).Elem()
+	if sv.IsZero() {
+		return nil
+	}
+	if err := l.define(key, pos); err != nil {
+		return err
+	}
+	reflect.ValueOf(dst).Elem().Set(sv)
+	return nil
+}
+
+// define records the position of key's definition and reports if it
+// was already defined.
+func (l *configLoader) define(key string, pos ConfigPos) error {
+	if other, ok := l.defs[key]; ok {
+		return errors.Errorf3(
+			"%s is defined in both %v and %v", key, other, pos,
+		)
+	}
+	l.defs[key] = pos
+	return nil
+}
+
+// mergePositions merges a file's positions.  The file's Targets are
+// appended after the first targets.
+func (l *configLoader) mergePositions(ps configPositions, targets int) {
+	for key, pos := range ps {
+		path := strings.Split(key, "\x00")
+		switch path[0] {
+		case "Include":
+			continue
+		case "Targets":
+			if len(path) > 1 {
+				i, _ := strconv.Atoi(path[1])
+				path[1] = strconv.Itoa(targets + i)
+				key = pathKey(path...)
+			}
+		}
+		if _, ok := l.files.positions[key]; !ok {
+			l.files.positions[key] = pos
+		}
+	}
+}
+
+// rebase makes the path relative to dir relative to the root
+// configuration file's directory instead.
+func (l *configLoader) rebase(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) || dir == l.dir {
+		return path
+	}
+	path = filepath.Join(dir, filepath.FromSlash(path))
+	if rel, err := filepath.Rel(l.dir, path); err == nil {
+		return rel
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -1,9 +1,12 @@
 package sqlmodelgen
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 	"io/ioutil"
+	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/skillian/logging"
@@ -11,8 +14,8 @@ import (
 	"github.com/skillian/expr/errors"
 	"github.com/skillian/expr/internal"
 	"github.com/skillian/expr/stream/sqlstream"
-	"github.com/skillian/expr/stream/sqlstream/sqlmodelgen/config"
 	"github.com/skillian/expr/stream/sqlstream/sqltypes"
+	"github.com/skillian/sqlmodel/config"
 )
 
 var logger = logging.GetLogger("sqlmodelgen")
@@ -32,6 +35,42 @@ type Config struct {
 	Databases       []*Database
 	DatabasesByName map[string]*Database
 	DatabaseNamers  Namers
+
+	// GoTags are the struct tags added to the fields of generated
+	// Go models.
+	GoTags []*GoTag
+
+	// CSSupportNamespace is the namespace that declares the support
+	// types of generated C# models.  If it is empty, the support types
+	// are generated.
+	CSSupportNamespace string
+
+	// CSModelKind is the kind of C# type generated for each model
+	// (e.g. "class" or "record").
+	CSModelKind string
+
+	// CSInitOnly generates init-only C# property setters.
+	CSInitOnly bool
+
+	// CSRequired marks the C# properties of non-nullable columns as
+	// required.
+	CSRequired bool
+
+	// CSNullable enables C# nullable reference types.
+	CSNullable bool
+
+	// CSJSON is the JSON library that C# ID and key converters are
+	// generated for or empty if none are generated.
+	CSJSON string
+
+	// CSJSONAttributes attributes C# ID and key types with their JSON
+	// converters.
+	CSJSONAttributes bool
+
+	// WVAceClassPrefix selects how WorkView class names are
+	// prefixed:  "database", "none" or empty to prefix them only
+	// when a workbook holds more than one database.
+	WVAceClassPrefix string
 }
 
 // ConfigFromJSON reads JSON data from the reader, r, and
@@ -42,13 +81,13 @@ func ConfigFromJSON(r io.Reader, mc ModelContext) (*Config, error) {
 		return nil, errors.Errorf1From(
 			err, "failed to load JSON from %v", r)
 	}
-	var j config.Config
-	if err = json.Unmarshal(data, &j); err != nil {
+	j, err := ReadJSONConfig(bytes.NewReader(data))
+	if err != nil {
 		return nil, errors.Errorf1From(
 			err, "failed to parse %q as JSON", data)
 	}
-	c := &Config{}
-	if err = (&configBuilder{Config: c, ModelContext: mc}).init(&j); err != nil {
+	c, err := NewConfig(j, mc)
+	if err != nil {
 		js, err2 := json.MarshalIndent(j, "", "\t")
 		if err2 != nil {
 			js = []byte("(error!)")
@@ -67,6 +106,15 @@ func ConfigFromJSON(r io.Reader, mc ModelContext) (*Config, error) {
 	return c, nil
 }
 
+// NewConfig instantiates a Config model from its configuration, j.
+func NewConfig(j *config.Config, mc ModelContext) (*Config, error) {
+	c := &Config{}
+	if err := (&configBuilder{Config: c, ModelContext: mc}).init(j); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
 func (c *Config) MarshalJSON() (Bs []byte, Err error) {
 	textOf := func(v interface{}) (string, error) {
 		if m, ok := v.(encoding.TextMarshaler); ok {
@@ -206,23 +254,55 @@ type Namers struct {
 	ModelNamer sqlstream.Namer
 }
 
+// namersByName are the namers that configurations select by name.  The
+// empty name selects the nopNamer.
+var namersByName = map[string]sqlstream.Namer{
+	"":           nopNamer{},
+	"default":    &sqlstream.DefaultCase,
+	"camelcase":  sqlstream.CamelCase,
+	"pascalcase": sqlstream.PascalCase,
+	"snakecase":  sqlstream.SnakeCase,
+}
+
+// NamerNames gets the sorted names of the namers that configurations can
+// select.
+func NamerNames() []string {
+	names := make([]string, 0, len(namersByName))
+	for name := range namersByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// namerFromName gets the namer selected by name.
+func namerFromName(name string) (sqlstream.Namer, error) {
+	if nr, ok := namersByName[name]; ok {
+		return nr, nil
+	}
+	return nil, errors.Errorf2(
+		"unknown namer %q (namers: %s)",
+		name, strings.Join(NamerNames()[1:], ", "),
+	)
+}
+
 // TODO: Change Namer to accept a context
 
 func (nrs *Namers) init(c *config.Namers) error {
-	nr, err := sqlstream.NamerFromName(c.SQLNamer)
+	nr, err := namerFromName(c.SQLNamer)
 	if err != nil {
-		return errors.Errorf1From(
+		return configErrorAt(errors.Errorf1From(
 			err, "failed to initialize SQL namer: %q",
 			c.SQLNamer,
-		)
+		), "SQLNamer")
 	}
 	nrs.SQLNamer = nr
-	nrs.ModelNamer, err = sqlstream.NamerFromName(c.ModelNamer)
+	nrs.ModelNamer, err = namerFromName(c.ModelNamer)
 	if err != nil {
-		return errors.Errorf1From(
+		return configErrorAt(errors.Errorf1From(
 			err, "failed to initialize model namer: %q",
 			c.ModelNamer,
-		)
+		), "ModelNamer")
 	}
 	return nil
 }
@@ -293,16 +373,54 @@ type Table struct {
 	// these columns from here instead of checking if columns are PK
 	// or Keys results in less logic in the templates.
 	DataColumns []*Column
+
+	// FKs are the columns of this table that reference another
+	// table's ID.
+	FKs []*Column
+
+	// FieldColumns are the table's columns in the order that their
+	// fields appear in the model:  The PK or Key columns, then the
+	// non-key FKs and finally the DataColumns.
+	FieldColumns []*Column
+
+	// Refs are the columns of other tables (or this one, if it
+	// references itself) whose FKs refer to one of this table's
+	// IDs.
+	Refs []*Column
+
+	// WorkView holds the metadata of the table's WorkView class.
+	WorkView config.WorkViewTable
+}
+
+func (t *Table) initFieldColumns() {
+	t.FieldColumns = make([]*Column, 0, len(t.Columns))
+	if t.PK != nil {
+		t.FieldColumns = append(t.FieldColumns, t.PK.Column)
+	} else if t.Key != nil {
+		for _, id := range t.Key.IDs {
+			t.FieldColumns = append(t.FieldColumns, id.Column)
+		}
+	}
+	for _, c := range t.FKs {
+		if !c.PK {
+			t.FieldColumns = append(t.FieldColumns, c)
+		}
+	}
+	t.FieldColumns = append(t.FieldColumns, t.DataColumns...)
 }
 
 type TableID struct {
 	Names
 	Column *Column
+
+	// Refs are the columns whose FKs refer to this ID.
+	Refs []*Column
 }
 
 type TableKey struct {
 	Names
-	IDs []*TableID
+	Table *Table
+	IDs   []*TableID
 }
 
 type Column struct {
@@ -311,6 +429,18 @@ type Column struct {
 	Type sqltypes.Type
 	PK   bool
 	FK   *TableID
+
+	// ID is non-nil if the column is its table's PK or a component
+	// of its table's Key.
+	ID *TableID
+
+	// Tags overrides the Go struct tags of the column's field.  An
+	// empty value omits the tag.
+	Tags map[string]string
+
+	// WorkView holds the metadata of the column's WorkView
+	// attribute.
+	WorkView config.WorkViewColumn
 }
 
 type View Table
@@ -334,45 +464,93 @@ func (b *configBuilder) init(c *config.Config) (err error) {
 	b.namespaces = make(map[string]struct{}, 8)
 	tempIDs := make([]*TableID, 0, 16)
 	if err = b.Config.DatabaseNamers.init(&c.DatabaseNamers); err != nil {
-		return
+		return configErrorAt(err, "DatabaseNamers")
 	}
 	b.Config.Namespace = c.Namespace
+	if err = b.initCS(&c.CS); err != nil {
+		return configErrorAt(err, "CS")
+	}
+	switch c.WorkView.ClassPrefix {
+	case "", "database", "none":
+		b.Config.WVAceClassPrefix = c.WorkView.ClassPrefix
+	default:
+		return configErrorAt(errors.Errorf1(
+			"invalid WorkView class prefix: %q",
+			c.WorkView.ClassPrefix,
+		), "WorkView", "ClassPrefix")
+	}
+	if err = b.initGoTags(c.Go.Tags); err != nil {
+		return configErrorAt(err, "Go", "Tags")
+	}
 	b.Config.Databases = make([]*Database, 0, len(c.Databases))
 	b.Config.DatabasesByName = make(map[string]*Database, len(c.Databases))
-	for dbName, dbCfg := range c.Databases {
+	for _, dbName := range sortedKeys(c.Databases) {
+		dbCfg := c.Databases[dbName]
 		d, dbErr := b.newDatabase(dbName, &dbCfg)
 		if dbErr != nil {
-			return errors.Errorf1From(
+			return configErrorAt(errors.Errorf1From(
 				dbErr, "failed to initialize "+
-					"database: %q", dbName)
+					"database: %q", dbName), "Databases", dbName)
 		}
 		b.Databases = append(b.Databases, d)
 		b.DatabasesByName[dbName] = d
-		for schName, schCfg := range dbCfg.Schemas {
+		for _, schName := range sortedKeys(dbCfg.Schemas) {
+			schCfg := dbCfg.Schemas[schName]
 			s := b.newSchema(d, schName, &schCfg)
 			d.Schemas = append(d.Schemas, s)
 			d.SchemasByName[schName] = s
-			for tblName, tblCfg := range schCfg.Tables {
+			for _, tblName := range sortedKeys(schCfg.Tables) {
+				tblCfg := schCfg.Tables[tblName]
 				t := b.newTable(s, tblName, &tblCfg)
 				s.Tables = append(s.Tables, t)
 				s.TablesByName[tblName] = t
-				for colName, colCfg := range tblCfg.Columns {
+				if err := checkWVAceLists(map[string][]string{
+					"Filters":  t.WorkView.Filters,
+					"Views":    t.WorkView.Views,
+					"Sections": t.WorkView.Sections,
+				}); err != nil {
+					return configErrorAt(
+						err, "Databases", dbName, "Schemas",
+						schName, "Tables", tblName,
+					)
+				}
+				for _, colName := range sortedKeys(tblCfg.Columns) {
+					colCfg := tblCfg.Columns[colName]
 					c := b.newColumn(t, colName, &colCfg)
 					t.Columns = append(t.Columns, c)
 					t.ColumnsByName[colName] = c
 					c.PK = colCfg.PK
+					c.Tags = colCfg.Tags
+					c.WorkView = colCfg.WorkView
+					if err := checkWVAceLists(map[string][]string{
+						"Filters":  c.WorkView.Filters,
+						"Views":    c.WorkView.Views,
+						"Sections": c.WorkView.Sections,
+						"DataSet":  c.WorkView.DataSet,
+					}); err != nil {
+						return configErrorAt(
+							err, "Databases", dbName, "Schemas",
+							schName, "Tables", tblName,
+							"Columns", colName,
+						)
+					}
 					if colCfg.Type != "" {
-						c.Type, err = sqltypes.Parse(colCfg.Type)
+						typePath := []string{
+							"Databases", dbName, "Schemas", schName,
+							"Tables", tblName, "Columns", colName,
+							"Type",
+						}
+						c.Type, err = ParseSQLType(colCfg.Type)
 						if err != nil {
-							return errors.ErrorfFrom(
+							return configErrorAt(errors.ErrorfFrom(
 								err,
 								"column %s.%s.%s.%s has an invalid Type",
 								dbName, schName, tblName, colName,
-							)
+							), typePath...)
 						}
 						ns, _, err := b.ModelType(c.Type)
 						if err != nil {
-							return errors.ErrorfFrom(
+							return configErrorAt(errors.ErrorfFrom(
 								err,
 								"failed to determine "+
 									"model type of "+
@@ -380,7 +558,7 @@ func (b *configBuilder) init(c *config.Config) (err error) {
 									"%s.%s.%s.%s",
 								dbName, schName,
 								tblName, colName,
-							)
+							), typePath...)
 						}
 						if len(ns) > 0 {
 							b.namespaces[ns] = struct{}{}
@@ -407,19 +585,23 @@ func (b *configBuilder) init(c *config.Config) (err error) {
 		if x.colCfg.FK == "" {
 			return nil
 		}
+		fkPath := []string{
+			"Databases", x.dbName, "Schemas", x.schName,
+			"Tables", x.tblName, "Columns", x.colName, "FK",
+		}
 		fkTrg, err := b.getPathUp(x.colCfg.FK, x.table)
 		if err != nil {
-			return errors.ErrorfFrom(
+			return configErrorAt(errors.ErrorfFrom(
 				err, "failed to initialize column %v.%v.%v.%v FK",
 				x.dbName, x.schName, x.tblName, x.colName,
-			)
+			), fkPath...)
 		}
 		fkCol, ok := fkTrg.(*Column)
 		if !ok {
-			return errors.Errorf(
+			return configErrorAt(errors.Errorf(
 				"column %v.%v.%v.%v FK target is not a column",
 				x.dbName, x.schName, x.tblName, x.colName,
-			)
+			), fkPath...)
 		}
 		fkTbl := fkCol.Table
 		if fkTbl.PK != nil && fkTbl.PK.Column == fkCol {
@@ -439,10 +621,13 @@ func (b *configBuilder) init(c *config.Config) (err error) {
 			}
 		}
 		if x.column.FK == nil {
-			return errors.Errorf(
+			return configErrorAt(errors.Errorf(
 				"column %q is not key within primary table %q",
-				fkCol.RawName, fkCol.Table.RawName)
+				fkCol.RawName, fkCol.Table.RawName), fkPath...)
 		}
+		x.column.FK.Refs = append(x.column.FK.Refs, x.column)
+		x.table.FKs = append(x.table.FKs, x.column)
+		fkTbl.Refs = append(fkTbl.Refs, x.column)
 		return nil
 	}); err != nil {
 		return err
@@ -466,6 +651,13 @@ func (b *configBuilder) init(c *config.Config) (err error) {
 	}); err != nil {
 		return err
 	}
+	for _, db := range b.Config.Databases {
+		for _, sch := range db.Schemas {
+			for _, tbl := range sch.Tables {
+				tbl.initFieldColumns()
+			}
+		}
+	}
 	if ens, ok := b.ModelContext.(NamespaceEnsurer); ok {
 		for _, ns := range ens.EnsureNamespaces(b.Config) {
 			if ns == "" {
@@ -478,6 +670,7 @@ func (b *configBuilder) init(c *config.Config) (err error) {
 	for ns := range b.namespaces {
 		b.Config.Namespaces = append(b.Config.Namespaces, ns)
 	}
+	sort.Strings(b.Config.Namespaces)
 	logger.Debug1("namespaces: %+v", b.Config.Namespaces)
 	if org, ok := b.ModelContext.(NamespaceOrganizer); ok {
 		b.Config.Namespaces = org.OrganizeNamespaces(b.Config.Namespaces)
@@ -485,6 +678,18 @@ func (b *configBuilder) init(c *config.Config) (err error) {
 	return
 }
 
+// sortedKeys gets the sorted keys of a map with string keys so that
+// configurations are always built in the same order.
+func sortedKeys(m interface{}) []string {
+	keys := reflect.ValueOf(m).MapKeys()
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = k.String()
+	}
+	sort.Strings(names)
+	return names
+}
+
 type dbSchemaTableColumn struct {
 	dbName  string
 	dbCfg   config.Database
@@ -501,13 +706,17 @@ type dbSchemaTableColumn struct {
 }
 
 func (b *configBuilder) iterDBSchemaTableColumn(c *config.Config, f func(dbSchemaTableColumn) error) error {
-	for dbName, dbCfg := range c.Databases {
+	for _, dbName := range sortedKeys(c.Databases) {
+		dbCfg := c.Databases[dbName]
 		db := b.Config.DatabasesByName[dbName]
-		for schName, schCfg := range dbCfg.Schemas {
+		for _, schName := range sortedKeys(dbCfg.Schemas) {
+			schCfg := dbCfg.Schemas[schName]
 			schema := db.SchemasByName[schName]
-			for tblName, tblCfg := range schCfg.Tables {
+			for _, tblName := range sortedKeys(schCfg.Tables) {
+				tblCfg := schCfg.Tables[tblName]
 				table := schema.TablesByName[tblName]
-				for colName, colCfg := range tblCfg.Columns {
+				for _, colName := range sortedKeys(tblCfg.Columns) {
+					colCfg := tblCfg.Columns[colName]
 					column := table.ColumnsByName[colName]
 					if err := f(dbSchemaTableColumn{
 						dbName, dbCfg, db,
@@ -611,6 +820,7 @@ func (b *configBuilder) newID(c *Column, cfg *config.Column) (id *TableID) {
 	b.caches.ids = b.caches.ids[1:]
 	id.Column = c
 	id.Names.init(c.RawName, &c.Table.Database.Namers.ID)
+	c.ID = id
 	return
 }
 
@@ -621,6 +831,7 @@ func (b *configBuilder) newKey(t *Table, ids []*TableID) (key *TableKey) {
 	key = &b.caches.keys[0]
 	b.caches.keys = b.caches.keys[1:]
 	key.Names.init(t.RawName+"Key", &t.Database.Namers.Key)
+	key.Table = t
 	key.IDs = b.newIDs(ids)
 	return
 }
@@ -635,6 +846,7 @@ func (b *configBuilder) newTable(s *Schema, name string, c *config.Table) (t *Ta
 	t.Names.init(name, &s.Database.Namers.Table)
 	t.Columns = make([]*Column, 0, len(c.Columns))
 	t.ColumnsByName = make(map[string]*Column, len(c.Columns))
+	t.WorkView = c.WorkView
 	return
 }
 
@@ -661,26 +873,26 @@ func (b *configBuilder) newDatabase(name string, c *config.Database) (d *Databas
 	d.Names.init(name, &b.DatabaseNamers)
 	d.Schemas = make([]*Schema, 0, len(c.Schemas))
 	d.SchemasByName = make(map[string]*Schema, len(c.Schemas))
-	initNamers := func(ofWhat string, nrs *Namers, c *config.Namers) (err error) {
+	initNamers := func(ofWhat, field string, nrs *Namers, c *config.Namers) (err error) {
 		if err = nrs.init(c); err != nil {
-			return errors.Errorf1From(
-				err, "failed to initialize %s", ofWhat)
+			return configErrorAt(errors.Errorf1From(
+				err, "failed to initialize %s", ofWhat), "Namers", field)
 		}
 		return
 	}
-	if err = initNamers("column", &d.Namers.Column, &c.Namers.Column); err != nil {
+	if err = initNamers("column", "Column", &d.Namers.Column, &c.Namers.Column); err != nil {
 		return
 	}
-	if err = initNamers("id", &d.Namers.ID, &c.Namers.IDType); err != nil {
+	if err = initNamers("id", "IDType", &d.Namers.ID, &c.Namers.IDType); err != nil {
 		return
 	}
-	if err = initNamers("key", &d.Namers.Key, &c.Namers.KeyType); err != nil {
+	if err = initNamers("key", "KeyType", &d.Namers.Key, &c.Namers.KeyType); err != nil {
 		return
 	}
-	if err = initNamers("table", &d.Namers.Table, &c.Namers.Table); err != nil {
+	if err = initNamers("table", "Table", &d.Namers.Table, &c.Namers.Table); err != nil {
 		return
 	}
-	if err = initNamers("schema", &d.Namers.Schema, &c.Namers.Schema); err != nil {
+	if err = initNamers("schema", "Schema", &d.Namers.Schema, &c.Namers.Schema); err != nil {
 		return
 	}
 	return
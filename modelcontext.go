@@ -3,6 +3,8 @@ package sqlmodelgen
 import (
 	"io"
 	"io/fs"
+	"sort"
+	"text/template"
 
 	//"github.com/skillian/expr/errors"
 	"github.com/skillian/expr/stream/sqlstream/sqltypes"
@@ -23,6 +25,14 @@ type TemplateContext interface {
 	FS() fs.FS
 }
 
+// FuncMapper is an optional interface that TemplateContexts can implement
+// to add context-specific functions to their templates.
+type FuncMapper interface {
+	// FuncMap returns the functions to add to the templates.  They do
+	// not overwrite the functions added by AddFuncs.
+	FuncMap() template.FuncMap
+}
+
 // NamespaceEnsurer is an optional interface that ModelContexts can implement
 // to inspect the initialized configuration and return namespaces that must
 // exist in the generated templates.
@@ -41,8 +51,81 @@ type NamespaceOrganizer interface {
 	OrganizeNamespaces(ns []string) []string
 }
 
+// OutputFormatter is an optional interface that TemplateContexts can
+// implement to format (and validate) the output of their templates before
+// it is written.
+type OutputFormatter interface {
+	// FormatOutput receives the complete output of the templates and
+	// returns the formatted output.  An error is returned if the
+	// output is not valid.
+	FormatOutput(src []byte) ([]byte, error)
+}
+
+// FileSplitter is an optional interface that TemplateContexts can implement
+// to write their output as multiple files into a directory instead of
+// into a single file.
+type FileSplitter interface {
+	// SplitFiles gets the files that make up the output.
+	SplitFiles(c *Config) ([]OutputFile, error)
+}
+
+// OutputFile is a single file of a FileSplitter's output.
+type OutputFile struct {
+	// Path of the file relative to the output directory.  Its
+	// elements are separated by slashes.
+	Path string
+
+	// Template is the name of the template that generates the file.
+	Template string
+
+	// Data is executed with the Template.
+	Data interface{}
+}
+
 // ModelWriter can be implemented instead of TemplateContext to write arbitrary
 // output right into an output file.
 type ModelWriter interface {
 	WriteModel(w io.Writer, c *Config) error
 }
+
+// ModelSplitter is an optional interface that ModelWriters can implement
+// to write their output as multiple files into a directory instead of
+// into a single file.
+type ModelSplitter interface {
+	// SplitModel gets the files that make up the output.
+	SplitModel(c *Config) ([]ModelFile, error)
+}
+
+// ModelFile is a single file of a ModelSplitter's output.
+type ModelFile struct {
+	// Path of the file relative to the output directory.  Its
+	// elements are separated by slashes.
+	Path string
+
+	// Write writes the file's content.
+	Write func(w io.Writer) error
+}
+
+// ModelContextByName gets the built-in ModelContext with the given name
+// (e.g. "cs" or "go").
+func ModelContextByName(name string) (mc ModelContext, ok bool) {
+	mc, ok = modelContexts[name]
+	return
+}
+
+// ModelContextNames gets the sorted names of the built-in ModelContexts.
+func ModelContextNames() []string {
+	names := make([]string, 0, len(modelContexts))
+	for name := range modelContexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var modelContexts = map[string]ModelContext{
+	"cs":    CSModelContext,
+	"csef":  CSEFCoreModelContext,
+	"go":    GoModelContext,
+	"wvace": WVAceModelContext,
+}
@@ -0,0 +1,355 @@
+package sqlmodelgen
+
+import (
+	"bytes"
+	"encoding/json"
+	goerrors "errors"
+	"io"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/skillian/expr/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// lineCounter translates byte offsets within a file into positions.
+// Offsets are counted from the last offset so that increasing offsets
+// are translated quickly.
+type lineCounter struct {
+	file string
+	data []byte
+
+	// offset is the last offset and line and lineStart are the line
+	// number and the offset of the line at that offset.
+	offset    int
+	line      int
+	lineStart int
+}
+
+func newLineCounter(file string, data []byte) *lineCounter {
+	return &lineCounter{file: file, data: data, line: 1}
+}
+
+func (lc *lineCounter) pos(offset int) ConfigPos {
+	if offset > len(lc.data) {
+		offset = len(lc.data)
+	}
+	if offset < lc.offset {
+		// e.g. the offsets of syntax errors can precede the last
+		// token's offset.
+		lc.offset, lc.line, lc.lineStart = 0, 1, 0
+	}
+	for ; lc.offset < offset; lc.offset++ {
+		if lc.data[lc.offset] == '\n' {
+			lc.line++
+			lc.lineStart = lc.offset + 1
+		}
+	}
+	return ConfigPos{
+		File:   lc.file,
+		Line:   lc.line,
+		Column: utf8.RuneCount(lc.data[lc.lineStart:offset]) + 1,
+	}
+}
+
+// parseJSONConfig parses a JSON configuration file's data.
+func parseJSONConfig(file string, data []byte) (*configNode, error) {
+	p := jsonParser{
+		dec:   json.NewDecoder(bytes.NewReader(data)),
+		data:  data,
+		lines: newLineCounter(file, data),
+	}
+	p.dec.UseNumber()
+	n, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	pos := p.pos()
+	if _, err = p.dec.Token(); err != io.EOF {
+		return nil, errors.Errorf1(
+			"%v: unexpected data after the configuration", pos,
+		)
+	}
+	return n, nil
+}
+
+type jsonParser struct {
+	dec   *json.Decoder
+	data  []byte
+	lines *lineCounter
+}
+
+// pos gets the position of the next token.
+func (p *jsonParser) pos() ConfigPos {
+	offset := int(p.dec.InputOffset())
+	for offset < len(p.data) {
+		switch p.data[offset] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			offset++
+			continue
+		}
+		break
+	}
+	return p.lines.pos(offset)
+}
+
+func (p *jsonParser) token() (ConfigPos, json.Token, error) {
+	pos := p.pos()
+	tok, err := p.dec.Token()
+	if err != nil {
+		if se, ok := err.(*json.SyntaxError); ok {
+			pos = p.syntaxErrorPos(se)
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return pos, nil, errors.Errorf1From(err, "%v: invalid JSON", pos)
+	}
+	return pos, tok, nil
+}
+
+// syntaxErrorPos gets the position of the byte that caused the syntax
+// error or, if the data ended too early, of the end of the data.
+func (p *jsonParser) syntaxErrorPos(se *json.SyntaxError) ConfigPos {
+	offset := int(se.Offset)
+	if offset > 0 && se.Error() != "unexpected end of JSON input" {
+		// the error occurred after reading the byte at Offset-1.
+		offset--
+	}
+	return p.lines.pos(offset)
+}
+
+func (p *jsonParser) value() (*configNode, error) {
+	pos, tok, err := p.token()
+	if err != nil {
+		return nil, err
+	}
+	n := &configNode{pos: pos, value: tok}
+	switch tok := tok.(type) {
+	case nil:
+		n.kind = nullNode
+	case bool:
+		n.kind = boolNode
+	case json.Number:
+		n.kind, n.value = numberNode, string(tok)
+	case string:
+		n.kind = stringNode
+	case json.Delim:
+		n.value = nil
+		if tok == '[' {
+			n.kind = arrayNode
+			for p.dec.More() {
+				item, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				n.items = append(n.items, item)
+			}
+		} else {
+			n.kind = objectNode
+			for p.dec.More() {
+				pos, key, err := p.token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				n.fields = append(n.fields, configField{
+					key: key.(string), pos: pos, value: value,
+				})
+			}
+		}
+		// the closing delimiter:
+		if _, _, err = p.token(); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// parseYAMLConfig parses a YAML configuration file's data.  Aliases are
+// expanded and merge keys ("<<") are merged.
+func parseYAMLConfig(file string, data []byte) (*configNode, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		// yaml.v3 only reports the lines of syntax errors within
+		// their messages.
+		pos := ConfigPos{File: file}
+		msg := err.Error()
+		if m := yamlErrorRegexp.FindStringSubmatch(msg); m != nil {
+			pos.Line, _ = strconv.Atoi(m[1])
+			msg = m[2]
+		}
+		return nil, errors.ErrorfFrom(
+			goerrors.New(msg), "%v: invalid YAML", pos,
+		)
+	}
+	if len(doc.Content) == 0 {
+		return &configNode{pos: ConfigPos{File: file, Line: 1, Column: 1}}, nil
+	}
+	p := yamlParser{file: file, expanding: make(map[*yaml.Node]bool)}
+	return p.node(doc.Content[0])
+}
+
+var yamlErrorRegexp = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+type yamlParser struct {
+	file string
+
+	// expanding holds the anchored nodes of the aliases that are
+	// being expanded so that recursive aliases are detected.
+	expanding map[*yaml.Node]bool
+
+	// nodes counts the nodes that were parsed and aliasNodes counts
+	// those that were parsed while expanding aliases.
+	nodes      int
+	aliasNodes int
+}
+
+// Like yaml.v3's own decoder, the share of the parsed nodes that are
+// expanded from aliases is limited so that small documents with nested
+// aliases cannot expand without bound.  The limit decreases from 99% to
+// 10% as the number of nodes grows from yamlAliasRatioRangeLow to
+// yamlAliasRatioRangeHigh.
+const (
+	yamlAliasRatioRangeLow  = 400000
+	yamlAliasRatioRangeHigh = 4000000
+)
+
+func yamlAllowedAliasRatio(nodes int) float64 {
+	switch {
+	case nodes <= yamlAliasRatioRangeLow:
+		return 0.99
+	case nodes >= yamlAliasRatioRangeHigh:
+		return 0.10
+	}
+	return 0.99 - 0.89*(float64(nodes-yamlAliasRatioRangeLow)/
+		float64(yamlAliasRatioRangeHigh-yamlAliasRatioRangeLow))
+}
+
+func (p *yamlParser) pos(n *yaml.Node) ConfigPos {
+	return ConfigPos{File: p.file, Line: n.Line, Column: n.Column}
+}
+
+func (p *yamlParser) node(n *yaml.Node) (*configNode, error) {
+	pos := p.pos(n)
+	p.nodes++
+	if len(p.expanding) > 0 {
+		p.aliasNodes++
+		if p.aliasNodes > 100 && p.nodes > 1000 &&
+			float64(p.aliasNodes)/float64(p.nodes) > yamlAllowedAliasRatio(p.nodes) {
+			return nil, errors.Errorf1(
+				"%v: invalid YAML: excessive aliasing", pos,
+			)
+		}
+	}
+	switch n.Kind {
+	case yaml.DocumentNode:
+		return p.node(n.Content[0])
+	case yaml.AliasNode:
+		if p.expanding[n.Alias] {
+			return nil, errors.Errorf2(
+				"%v: invalid YAML: recursive alias %q",
+				pos, "*"+n.Value,
+			)
+		}
+		p.expanding[n.Alias] = true
+		c, err := p.node(n.Alias)
+		delete(p.expanding, n.Alias)
+		if err != nil {
+			return nil, err
+		}
+		alias := *c
+		alias.pos = pos
+		return &alias, nil
+	case yaml.ScalarNode:
+		switch n.ShortTag() {
+		case "!!null":
+			return &configNode{kind: nullNode, pos: pos}, nil
+		case "!!bool":
+			var b bool
+			if err := n.Decode(&b); err != nil {
+				return nil, errors.Errorf1From(err, "%v: invalid YAML", pos)
+			}
+			return &configNode{kind: boolNode, pos: pos, value: b}, nil
+		case "!!int", "!!float":
+			return &configNode{kind: numberNode, pos: pos, value: n.Value}, nil
+		}
+		return &configNode{kind: stringNode, pos: pos, value: n.Value}, nil
+	case yaml.SequenceNode:
+		c := &configNode{kind: arrayNode, pos: pos}
+		for _, item := range n.Content {
+			x, err := p.node(item)
+			if err != nil {
+				return nil, err
+			}
+			c.items = append(c.items, x)
+		}
+		return c, nil
+	case yaml.MappingNode:
+		c := &configNode{kind: objectNode, pos: pos}
+		var merged []configField
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.Kind != yaml.ScalarNode {
+				return nil, errors.Errorf1(
+					"%v: mapping keys must be scalars", p.pos(k),
+				)
+			}
+			x, err := p.node(v)
+			if err != nil {
+				return nil, err
+			}
+			if k.ShortTag() == "!!merge" {
+				fs, err := p.mergeFields(x)
+				if err != nil {
+					return nil, err
+				}
+				merged = append(merged, fs...)
+				continue
+			}
+			c.fields = append(c.fields, configField{
+				key: k.Value, pos: p.pos(k), value: x,
+			})
+		}
+		// explicit keys override merged keys and earlier merged
+		// mappings override later ones.
+		keys := make(map[string]struct{}, len(c.fields)+len(merged))
+		for _, f := range c.fields {
+			keys[f.key] = struct{}{}
+		}
+		for _, f := range merged {
+			if _, ok := keys[f.key]; !ok {
+				keys[f.key] = struct{}{}
+				c.fields = append(c.fields, f)
+			}
+		}
+		return c, nil
+	}
+	return nil, errors.Errorf1("%v: unexpected YAML node", pos)
+}
+
+// mergeFields gets the fields merged by a merge key's value:  a mapping
+// or a sequence of mappings.
+func (p *yamlParser) mergeFields(n *configNode) ([]configField, error) {
+	switch n.kind {
+	case objectNode:
+		return n.fields, nil
+	case arrayNode:
+		var fs []configField
+		for _, item := range n.items {
+			if item.kind != objectNode {
+				return nil, errors.Errorf1(
+					"%v: merge keys only merge mappings",
+					item.pos,
+				)
+			}
+			fs = append(fs, item.fields...)
+		}
+		return fs, nil
+	}
+	return nil, errors.Errorf1("%v: merge keys only merge mappings", n.pos)
+}
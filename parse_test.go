@@ -0,0 +1,98 @@
+package sqlmodelgen
+
+import (
+	"strings"
+	"testing"
+)
+
+// firstLine gets the first line of an error's message, before the
+// messages and stack frames of the errors that it wraps.
+func firstLine(err error) string {
+	msg := err.Error()
+	if i := strings.IndexByte(msg, '\n'); i != -1 {
+		msg = msg[:i]
+	}
+	return msg
+}
+
+func TestParseMalformedConfigs(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+
+		// want is the prefix of the error's first line.  yaml.v3 does
+		// not report the lines of some errors.
+		want string
+	}{
+		{"colon.json", "{\"Namespace\":\"x\":\n1}", "colon.json:1:17: invalid JSON"},
+		{"comma.json", "{\"Namespace\": \"x\",\n \"Databases\": {\n  \"a\": 1,,\n}}", "comma.json:3:10: invalid JSON"},
+		{"eof.json", `{"Namespace": "x"`, "eof.json:1:18: invalid JSON"},
+		{"empty.json", "", "empty.json:1:1: invalid JSON"},
+		{"unquoted.json", "{\n\tNamespace: 1}", "unquoted.json:2:2: invalid JSON"},
+		{"multibyte.json", "{\"Namespace\": \"éé\" x}", "multibyte.json:1:20: invalid JSON"},
+		{"trailing.json", `{"Namespace": "x"} 1`, "trailing.json:1:20: unexpected data"},
+		{"value.toml", "Namespace = \n", "value.toml:1:13: invalid TOML"},
+		{"duplicate.toml", "Namespace = \"a\"\nNamespace = \"b\"\n", "duplicate.toml:2:1: invalid TOML"},
+		{"string.toml", "Namespace = \"a\n", "string.toml:1:15: invalid TOML"},
+		{"indent.yaml", "Namespace: x\n  Databases: {}\n", "indent.yaml:2: invalid YAML"},
+		{"flow.yaml", "Databases: {a: [}\n", "flow.yaml: invalid YAML"},
+		{"recursive.yaml", "Databases: &x\n  db: *x\n", "recursive.yaml:2:7: invalid YAML: recursive alias \"*x\""},
+		{"laughs.yaml", billionLaughs(), "laughs.yaml:1:31: invalid YAML: excessive aliasing"},
+	}
+	parsers := map[string]func(string, []byte) (*configNode, error){
+		".json": parseJSONConfig,
+		".toml": parseTOMLConfig,
+		".yaml": parseYAMLConfig,
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			parse := parsers[tc.name[strings.LastIndexByte(tc.name, '.'):]]
+			_, _, err := readConfig(tc.name, strings.NewReader(tc.data), parse)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if got := firstLine(err); !strings.HasPrefix(got, tc.want) {
+				t.Fatalf("got %q, want prefix %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// billionLaughs gets a YAML document whose nested aliases expand to
+// 10^9 nodes.
+func billionLaughs() string {
+	var b strings.Builder
+	b.WriteString("x-a: &a [x, x, x, x, x, x, x, x, x, x]\n")
+	for c := 'b'; c <= 'i'; c++ {
+		b.WriteString("x-" + string(c) + ": &" + string(c) + " [")
+		for i := 0; i < 10; i++ {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString("*" + string(c-1))
+		}
+		b.WriteString("]\n")
+	}
+	return b.String()
+}
+
+func TestLineCounter(t *testing.T) {
+	data := []byte("ab\néc\nd")
+	lc := newLineCounter("f", data)
+	tests := []struct {
+		offset int
+		want   string
+	}{
+		{0, "f:1:1"},
+		{5, "f:2:2"},
+		{len(data), "f:3:2"},
+		// offsets may decrease:
+		{1, "f:1:2"},
+		{len(data) + 10, "f:3:2"},
+	}
+	for _, tc := range tests {
+		if got := lc.pos(tc.offset).String(); got != tc.want {
+			t.Errorf("pos(%d) = %q, want %q", tc.offset, got, tc.want)
+		}
+	}
+}
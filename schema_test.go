@@ -0,0 +1,53 @@
+package sqlmodelgen
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/skillian/sqlmodel/config"
+)
+
+// TestSchemaSQLTypePattern checks that the JSON Schema's pattern of
+// column types agrees with ParseSQLType, which ignores case.
+func TestSchemaSQLTypePattern(t *testing.T) {
+	var schema struct {
+		Definitions struct {
+			Column struct {
+				Properties struct {
+					Type struct {
+						Pattern string `json:"pattern"`
+					}
+				} `json:"properties"`
+			}
+		} `json:"definitions"`
+	}
+	if err := json.Unmarshal(config.JSONSchema, &schema); err != nil {
+		t.Fatal(err)
+	}
+	pattern, err := regexp.Compile(schema.Definitions.Column.Properties.Type.Pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		typ  string
+		want bool
+	}{
+		{"decimal(scale: 2, prec: 10)", true},
+		{"DECIMAL(Scale: 2, PREC: 10)", true},
+		{"Nullable(String(Length: 5, Var: true))", true},
+		{"datetz(MIN: 0001-01-01, Prec: 100ns)", true},
+		{"Duration(PREC: 1ms)", true},
+		{"BYTES(VAR: true)", true},
+		{"decimal(bogus: 1)", false},
+		{"string(length 5)", false},
+	}
+	for _, tc := range tests {
+		if got := pattern.MatchString(tc.typ); got != tc.want {
+			t.Errorf("%q matched: %t, want %t", tc.typ, got, tc.want)
+		}
+		if _, err := ParseSQLType(tc.typ); (err == nil) != tc.want {
+			t.Errorf("ParseSQLType(%q): %v", tc.typ, err)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/sqlmodel"
+)
+
+// stdoutMu serializes the diffs that concurrently checked targets write
+// to standard output.
+var stdoutMu sync.Mutex
+
+// checkOutputs renders the ModelContext's output into memory and
+// prints a unified diff of every output file that is missing or out of
+// date.  errFailed is returned if there are any.
+func checkOutputs(args Args, cfg *sqlmodelgen.Config) error {
+	if !args.Split && args.ModelFile == "" {
+		return usageError{errors.Errorf0(
+			"--check requires a modelfile",
+		)}
+	}
+	ofs, err := renderOutputs(args, cfg)
+	if err != nil {
+		return err
+	}
+	var diffs strings.Builder
+	for _, of := range ofs {
+		old, err := os.ReadFile(of.name)
+		oldName := of.name
+		switch {
+		case os.IsNotExist(err):
+			oldName = "/dev/null"
+		case err != nil:
+			return errors.Errorf1From(
+				err, "failed to read %v", of.name,
+			)
+		case bytes.Equal(old, of.data):
+			continue
+		}
+		diffs.WriteString(unifiedDiff(oldName, of.name, old, of.data))
+	}
+	if diffs.Len() == 0 {
+		return nil
+	}
+	stdoutMu.Lock()
+	defer stdoutMu.Unlock()
+	fmt.Print(diffs.String())
+	return errFailed
+}
+
+const (
+	// diffContext is the number of unchanged lines around the
+	// changes in each hunk of a unified diff.
+	diffContext = 3
+
+	// diffMaxEdits limits the number of edits that diffLines
+	// searches for before it gives up and replaces every differing
+	// line.
+	diffMaxEdits = 1000
+)
+
+// unifiedDiff formats the differences from a to b as a unified diff.
+func unifiedDiff(aName, bName string, a, b []byte) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", aName, bName)
+	if bytes.IndexByte(a, 0) != -1 || bytes.IndexByte(b, 0) != -1 {
+		fmt.Fprintf(&sb, "Binary files %s and %s differ\n", aName, bName)
+		return sb.String()
+	}
+	ops := diffLines(splitLines(a), splitLines(b))
+	// aLines[i] and bLines[i] are the numbers of lines of a and b
+	// before ops[i].
+	aLines := make([]int, len(ops)+1)
+	bLines := make([]int, len(ops)+1)
+	for i, op := range ops {
+		aLines[i+1], bLines[i+1] = aLines[i], bLines[i]
+		if op.kind != '+' {
+			aLines[i+1]++
+		}
+		if op.kind != '-' {
+			bLines[i+1]++
+		}
+	}
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		// extend the hunk through the changes separated by too
+		// few unchanged lines to start another hunk.
+		end := i
+		for {
+			for end < len(ops) && ops[end].kind != ' ' {
+				end++
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContext {
+				break
+			}
+			end = next
+		}
+		stop := end + diffContext
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+		fmt.Fprintf(
+			&sb, "@@ -%s +%s @@\n",
+			hunkRange(aLines[start], aLines[stop]),
+			hunkRange(bLines[start], bLines[stop]),
+		)
+		for _, op := range ops[start:stop] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = stop
+	}
+	return sb.String()
+}
+
+// hunkRange formats the range of lines after the first line through the
+// last one.
+func hunkRange(first, last int) string {
+	switch n := last - first; n {
+	case 0:
+		return fmt.Sprintf("%d,0", first)
+	case 1:
+		return fmt.Sprintf("%d", first+1)
+	default:
+		return fmt.Sprintf("%d,%d", first+1, n)
+	}
+}
+
+// splitLines splits data after each newline.
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffOp is a line of a diff that is unchanged (' '), removed ('-') or
+// added ('+').
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// diffLines gets the shortest sequence of diffOps that turns a into b.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// myersDiff implements Myers' diff algorithm.  If more than diffMaxEdits
+// edits are needed, all of a is removed and all of b is added instead.
+func myersDiff(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	max := n + m
+	if max > diffMaxEdits {
+		max = diffMaxEdits
+	}
+	// v[k+offset] is the furthest x reached on diagonal k.  trace[d]
+	// holds v[-d:d+1] before the d-th edit.
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return myersBacktrack(a, b, trace)
+			}
+		}
+	}
+	ops := make([]diffOp, 0, n+m)
+	for _, line := range a {
+		ops = append(ops, diffOp{'-', line})
+	}
+	for _, line := range b {
+		ops = append(ops, diffOp{'+', line})
+	}
+	return ops
+}
+
+func myersBacktrack(a, b []string, trace [][]int) []diffOp {
+	x, y := len(a), len(b)
+	var ops []diffOp
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[k-1+d] < v[k+1+d]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := 0
+		if d > 0 {
+			prevX = v[prevK+d]
+		}
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{' ', a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, diffOp{'+', b[y-1]})
+			} else {
+				ops = append(ops, diffOp{'-', a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+
+		// want is the diff after its file name lines.
+		want string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", ""},
+		{
+			"separate hunks",
+			"a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n",
+			"a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nL\nm\nn\n",
+			"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+				"@@ -9,5 +9,6 @@\n i\n j\n k\n-l\n+L\n m\n+n\n",
+		},
+		{
+			"merged hunk",
+			"a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n",
+			"a\nc\nd\ne\nf\ng\nH\ni\nj\n",
+			"@@ -1,10 +1,9 @@\n a\n-b\n c\n d\n e\n f\n g\n-h\n+H\n i\n j\n",
+		},
+		{
+			"no newline at end of file",
+			"x\ny",
+			"x\nz\n",
+			"@@ -1,2 +1,2 @@\n x\n-y\n\\ No newline at end of file\n+z\n",
+		},
+		{"added", "", "x\nz\n", "@@ -0,0 +1,2 @@\n+x\n+z\n"},
+		{"removed", "x\nz\n", "", "@@ -1,2 +0,0 @@\n-x\n-z\n"},
+		{"binary", "a\x00", "b\x00", "Binary files a and b differ\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := unifiedDiff("a", "b", []byte(tc.a), []byte(tc.b))
+			const header = "--- a\n+++ b\n"
+			if !strings.HasPrefix(got, header) {
+				t.Fatalf("%q does not start with %q", got, header)
+			}
+			if got = got[len(header):]; got != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestDiffLinesIsMinimal checks that the diffs of random line sequences
+// turn a into b with the fewest edits that their longest common
+// subsequence allows.
+func TestDiffLinesIsMinimal(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, r.Intn(12))
+		for i := range lines {
+			lines[i] = string(rune('a'+r.Intn(3))) + "\n"
+		}
+		return lines
+	}
+	for i := 0; i < 1000; i++ {
+		a, b := randomLines(), randomLines()
+		ops := diffLines(a, b)
+		var gotA, gotB []string
+		edits := 0
+		for _, op := range ops {
+			if op.kind != '+' {
+				gotA = append(gotA, op.line)
+			}
+			if op.kind != '-' {
+				gotB = append(gotB, op.line)
+			}
+			if op.kind != ' ' {
+				edits++
+			}
+		}
+		if strings.Join(gotA, "") != strings.Join(a, "") ||
+			strings.Join(gotB, "") != strings.Join(b, "") {
+			t.Fatalf("diff of %q and %q is %q", a, b, ops)
+		}
+		if want := len(a) + len(b) - 2*lcsLen(a, b); edits != want {
+			t.Fatalf("diff of %q and %q has %d edits, want %d", a, b, edits, want)
+		}
+	}
+}
+
+// lcsLen gets the length of the longest common subsequence of a and b.
+func lcsLen(a, b []string) int {
+	l := make([][]int, len(a)+1)
+	for i := range l {
+		l[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				l[i][j] = l[i+1][j+1] + 1
+			case l[i+1][j] > l[i][j+1]:
+				l[i][j] = l[i+1][j]
+			default:
+				l[i][j] = l[i][j+1]
+			}
+		}
+	}
+	return l[0][0]
+}
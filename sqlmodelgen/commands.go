@@ -0,0 +1,371 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/davecgh/go-spew/spew"
+
+	"github.com/skillian/argparse"
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/sqlmodel"
+	"github.com/skillian/sqlmodel/config"
+)
+
+// runCheck builds the ConfigFile's model and generates it into memory
+// for the ModelContext or, if none was specified, the configuration's
+// Targets or every ModelContext if it has none.
+func runCheck(args Args) error {
+	j, files, err := loadConfig(args, args.ConfigFile)
+	if err != nil {
+		return err
+	}
+	var ts []target
+	switch {
+	case args.ModelContext != nil:
+		ts = []target{{
+			name:   args.ModelContextName,
+			args:   args,
+			config: j,
+			files:  files,
+		}}
+	case len(j.Targets) > 0:
+		if ts, err = resolveTargets(args, j, files); err != nil {
+			return err
+		}
+	default:
+		for _, name := range sqlmodelgen.ModelContextNames() {
+			targs := args
+			targs.ModelContextName = name
+			targs.ModelContext, _ = sqlmodelgen.ModelContextByName(name)
+			ts = append(ts, target{
+				name: name, args: targs, config: j, files: files,
+			})
+		}
+	}
+	errs := make([]error, len(ts))
+	for i, t := range ts {
+		errs[i] = check(t)
+	}
+	if err = reportTargetErrors(args, ts, errs); err != nil {
+		return err
+	}
+	fmt.Printf("%s: ok\n", args.ConfigFile)
+	return nil
+}
+
+// check generates the target's model into memory.
+func check(t target) error {
+	cfg, err := newConfig(t)
+	if err != nil {
+		return err
+	}
+	_, err = renderOutputs(t.args, cfg)
+	return err
+}
+
+func addIntrospectArguments(p *argparse.ArgumentParser, args *Args) {
+	p.MustAddArgument(
+		argparse.OptionStrings("--driver"),
+		argparse.Action("store"),
+		argparse.Required,
+		argparse.Help(
+			"database/sql driver name: sqlserver, postgres or "+
+				"mysql unless they are excluded by the "+
+				"nomssql, nopostgres or nomysql build tags",
+		),
+	).MustBind(&args.Driver)
+	p.MustAddArgument(
+		argparse.OptionStrings("--dsn"),
+		argparse.Action("store"),
+		argparse.Required,
+		argparse.Help("driver-specific data source name"),
+	).MustBind(&args.DSN)
+	p.MustAddArgument(
+		argparse.OptionStrings("--database"),
+		argparse.Action("store"),
+		argparse.Required,
+		argparse.Help("name of the database in the configuration"),
+	).MustBind(&args.Database)
+	p.MustAddArgument(
+		argparse.OptionStrings("--schemas"),
+		argparse.Action("store"),
+		argparse.Default(""),
+		argparse.Help(
+			"comma-separated schemas to introspect (default: "+
+				"all of them)",
+		),
+	).MustBind(&args.Schemas)
+	p.MustAddArgument(
+		argparse.Dest("configfile"),
+		argparse.Action("store"),
+		argparse.Default(""),
+		argparse.Help(
+			"output JSON configuration file (default: "+
+				"standard output)",
+		),
+	).MustBind(&args.ModelFile)
+}
+
+// runIntrospect writes the configuration of an existing database.
+func runIntrospect(args Args) (Err error) {
+	drivers := sql.Drivers()
+	if i := sort.SearchStrings(drivers, args.Driver); i == len(drivers) || drivers[i] != args.Driver {
+		if len(drivers) == 0 {
+			return usageError{errors.Errorf1(
+				"unknown driver %q: no database/sql drivers "+
+					"are compiled in",
+				args.Driver,
+			)}
+		}
+		return usageError{errors.Errorf2(
+			"unknown driver %q (drivers: %s)",
+			args.Driver, strings.Join(drivers, ", "),
+		)}
+	}
+	db, err := sql.Open(args.Driver, args.DSN)
+	if err != nil {
+		return errors.Errorf1From(
+			err, "failed to open %v database", args.Driver,
+		)
+	}
+	defer errors.Catch(&Err, db.Close)
+	var schemas []string
+	for _, sch := range strings.Split(args.Schemas, ",") {
+		if sch = strings.TrimSpace(sch); sch != "" {
+			schemas = append(schemas, sch)
+		}
+	}
+	j, err := sqlmodelgen.Introspect(
+		context.Background(), db, args.Database, schemas...,
+	)
+	if err != nil {
+		return err
+	}
+	j.Namespace = args.Namespace
+	if args.ModelFile == "" {
+		return writeConfigJSON(os.Stdout, j)
+	}
+	f, err := os.Create(args.ModelFile)
+	if err != nil {
+		return errors.Errorf1From(
+			err, "failed to create output file: %v",
+			args.ModelFile,
+		)
+	}
+	defer errors.Catch(&Err, f.Close)
+	return writeConfigJSON(f, j)
+}
+
+// writeConfigJSON writes the configuration as indented JSON, omitting
+// the fields that are not set.
+func writeConfigJSON(w io.Writer, j *config.Config) error {
+	bs, err := json.Marshal(j)
+	if err != nil {
+		return errors.Errorf0From(err, "failed to marshal configuration")
+	}
+	var v interface{}
+	if err = json.Unmarshal(bs, &v); err != nil {
+		return errors.Errorf0From(err, "failed to marshal configuration")
+	}
+	v, _ = pruneJSON(v)
+	if bs, err = json.MarshalIndent(v, "", "\t"); err != nil {
+		return errors.Errorf0From(err, "failed to marshal configuration")
+	}
+	if _, err = w.Write(append(bs, '\n')); err != nil {
+		return errors.Errorf1From(
+			err, "failed to write output to %v", w,
+		)
+	}
+	return nil
+}
+
+// pruneJSON removes the zero values from unmarshaled JSON, v, and
+// returns false if v itself is zero.
+func pruneJSON(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case nil:
+		return nil, false
+	case bool:
+		return v, v
+	case float64:
+		return v, v != 0
+	case string:
+		return v, v != ""
+	case []interface{}:
+		return v, len(v) > 0
+	case map[string]interface{}:
+		for k, x := range v {
+			if x, ok := pruneJSON(x); ok {
+				v[k] = x
+			} else {
+				delete(v, k)
+			}
+		}
+		return v, len(v) > 0
+	}
+	return v, true
+}
+
+// runDiff reports the differences between the ConfigFile and the
+// OtherConfigFile.
+func runDiff(args Args) error {
+	a, _, err := loadConfig(args, args.ConfigFile)
+	if err != nil {
+		return err
+	}
+	b, _, err := loadConfig(args, args.OtherConfigFile)
+	if err != nil {
+		return err
+	}
+	diffs := diffConfigs(a, b)
+	for _, d := range diffs {
+		fmt.Println(d)
+	}
+	if len(diffs) > 0 {
+		return errFailed
+	}
+	return nil
+}
+
+// configItem is a database, schema, table, view or column of a
+// configuration.
+type configItem struct {
+	kind string
+
+	// attrs are the item's attributes that diff compares.
+	attrs [][2]string
+}
+
+// configItems gets the configuration's items by their dot-separated
+// paths.
+func configItems(c *config.Config) map[string]configItem {
+	items := make(map[string]configItem)
+	addTable := func(kind, path string, tbl config.Table) {
+		items[path] = configItem{kind: kind}
+		for colName, col := range tbl.Columns {
+			pk := ""
+			if col.PK {
+				pk = "true"
+			}
+			items[path+"."+colName] = configItem{
+				kind: "column",
+				attrs: [][2]string{
+					{"type", col.Type},
+					{"pk", pk},
+					{"fk", col.FK},
+				},
+			}
+		}
+	}
+	for dbName, db := range c.Databases {
+		items[dbName] = configItem{kind: "database"}
+		for schName, sch := range db.Schemas {
+			schPath := dbName + "." + schName
+			items[schPath] = configItem{kind: "schema"}
+			for tblName, tbl := range sch.Tables {
+				addTable("table", schPath+"."+tblName, tbl)
+			}
+			for vwName, vw := range sch.Views {
+				addTable("view", schPath+"."+vwName, config.Table(vw))
+			}
+		}
+	}
+	return items
+}
+
+// diffConfigs describes the items that are added ("+"), removed ("-")
+// or changed ("~") from a to b.  The items of added or removed parents
+// are not described.
+func diffConfigs(a, b *config.Config) []string {
+	as, bs := configItems(a), configItems(b)
+	paths := make([]string, 0, len(as)+len(bs))
+	for path := range as {
+		paths = append(paths, path)
+	}
+	for path := range bs {
+		if _, ok := as[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	// sort by path elements so that each item's children follow it.
+	sort.Slice(paths, func(i, j int) bool {
+		return strings.Replace(paths[i], ".", "\x00", -1) <
+			strings.Replace(paths[j], ".", "\x00", -1)
+	})
+	var diffs []string
+	skip := ""
+	for _, path := range paths {
+		if skip != "" && strings.HasPrefix(path, skip) {
+			continue
+		}
+		skip = ""
+		ai, inA := as[path]
+		bi, inB := bs[path]
+		switch {
+		case !inA:
+			diffs = append(diffs, fmt.Sprintf("+ %s %s", bi.kind, path))
+			skip = path + "."
+		case !inB:
+			diffs = append(diffs, fmt.Sprintf("- %s %s", ai.kind, path))
+			skip = path + "."
+		case ai.kind != bi.kind:
+			diffs = append(diffs, fmt.Sprintf(
+				"~ %s %s: %s -> %s", ai.kind, path, ai.kind, bi.kind,
+			))
+		default:
+			for i, attr := range ai.attrs {
+				if attr[1] == bi.attrs[i][1] {
+					continue
+				}
+				diffs = append(diffs, fmt.Sprintf(
+					"~ %s %s: %s %q -> %q",
+					ai.kind, path, attr[0], attr[1],
+					bi.attrs[i][1],
+				))
+			}
+		}
+	}
+	return diffs
+}
+
+// runDumpModel writes the ConfigFile's model to standard output.
+func runDumpModel(args Args) error {
+	if err := requireModelContext(args); err != nil {
+		return err
+	}
+	cfg, err := buildConfig(args)
+	if err != nil {
+		return err
+	}
+	spew.Fdump(os.Stdout, cfg)
+	return nil
+}
+
+// runSchema writes the JSON Schema of configuration files.
+func runSchema(args Args) error {
+	if args.ModelFile == "" {
+		if _, err := os.Stdout.Write(config.JSONSchema); err != nil {
+			return errors.Errorf0From(
+				err, "failed to write JSON Schema",
+			)
+		}
+		return nil
+	}
+	return writeFileIfChanged(args.ModelFile, config.JSONSchema)
+}
+
+// runListContexts writes the names of the ModelContexts to standard
+// output.
+func runListContexts(args Args) error {
+	for _, name := range sqlmodelgen.ModelContextNames() {
+		fmt.Println(name)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/skillian/sqlmodel"
+	"github.com/skillian/sqlmodel/config"
+)
+
+// exprDirEnv names the environment variable holding the directory of a
+// github.com/skillian/expr module that the generated Go models compile
+// against.  The Go compile tests are skipped if it is not set.
+const exprDirEnv = "SQLMODELGEN_EXPR_DIR"
+
+// generateTestModel generates the model of testdata/models.json, edited
+// by edit if it isn't nil, into dir.  The model is written into the
+// file, name, unless split is true.
+func generateTestModel(t *testing.T, contextName, dir, name string, split bool, edit func(*config.Config)) {
+	t.Helper()
+	j, _, err := sqlmodelgen.LoadConfig(filepath.Join("testdata", "models.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edit != nil {
+		edit(j)
+	}
+	mc, ok := sqlmodelgen.ModelContextByName(contextName)
+	if !ok {
+		t.Fatalf("unknown model context %q", contextName)
+	}
+	cfg, err := sqlmodelgen.NewConfig(j, mc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := Args{ModelContext: mc, ModelFile: filepath.Join(dir, name), Split: split}
+	if split {
+		args.ModelFile = dir
+	}
+	ofs, err := renderOutputs(args, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, of := range ofs {
+		if err := os.MkdirAll(filepath.Dir(of.name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(of.name, of.data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// runIn runs the command in dir and fails the test with its output if
+// it fails.
+func runIn(t *testing.T, dir, name string, arg ...string) {
+	t.Helper()
+	cmd := exec.Command(name, arg...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%s %v failed: %v\n%s", name, arg, err, out)
+	}
+}
+
+// copyTestFile copies the testdata file into dir.
+func copyTestFile(t *testing.T, name, dir string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestGoModelsCompile generates the Go models of testdata/models.json
+// and runs the tests of testdata/models_test.go against them.
+func TestGoModelsCompile(t *testing.T) {
+	exprDir := os.Getenv(exprDirEnv)
+	if exprDir == "" {
+		t.Skipf("%s is not set", exprDirEnv)
+	}
+	for _, split := range []bool{false, true} {
+		split := split
+		name := "single file"
+		if split {
+			name = "split"
+		}
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			generateTestModel(t, "go", dir, "models.go", split, nil)
+			copyTestFile(t, "models_test.go", dir)
+			gomod := "module models\n\ngo 1.16\n\n" +
+				"require github.com/skillian/expr v0.0.0\n\n" +
+				"replace github.com/skillian/expr => " + exprDir + "\n"
+			if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0644); err != nil {
+				t.Fatal(err)
+			}
+			runIn(t, dir, "go", "mod", "tidy")
+			runIn(t, dir, "go", "vet", ".")
+			runIn(t, dir, "go", "test", ".")
+		})
+	}
+}
+
+// csProject is the project that the generated C# models are built with.
+// Nullable warnings are errors so that the models of configurations
+// with CS.Nullable set must be free of them.
+const csProject = `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <OutputType>Library</OutputType>
+    <LangVersion>latest</LangVersion>
+    <WarningsAsErrors>nullable</WarningsAsErrors>
+  </PropertyGroup>
+</Project>
+`
+
+// TestCSModelsCompile builds the C# models of testdata/models.json with
+// every combination of the CS options that change their declarations.
+// Each combination is generated into its own namespace of one project.
+// It is skipped if the dotnet CLI is not installed.
+func TestCSModelsCompile(t *testing.T) {
+	if _, err := exec.LookPath("dotnet"); err != nil {
+		t.Skip("dotnet is not installed")
+	}
+	dir := t.TempDir()
+	n := 0
+	for _, kind := range []string{"class", "record", "record struct"} {
+		for _, nullable := range []bool{false, true} {
+			for _, required := range []bool{false, true} {
+				for _, split := range []bool{false, true} {
+					n++
+					cs := config.CS{
+						ModelKind: kind,
+						Nullable:  nullable,
+						Required:  required,
+						InitOnly:  required,
+					}
+					ns := fmt.Sprintf("Models%d", n)
+					generateTestModel(t, "cs", filepath.Join(dir, ns), "Models.cs", split, func(j *config.Config) {
+						j.Namespace = ns
+						j.CS = cs
+					})
+				}
+			}
+		}
+	}
+	// the templates' line endings must not be mixed in the output.
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.Count(data, []byte("\n")) != bytes.Count(data, []byte("\r\n")) {
+			t.Errorf("%s has lines that do not end with CRLF", path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Models.csproj"), []byte(csProject), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runIn(t, dir, "dotnet", "build", "-nologo")
+}
@@ -0,0 +1,9 @@
+//go:build !nomssql
+// +build !nomssql
+
+package main
+
+// The SQL Server driver is registered as "sqlserver" and "mssql" for
+// the introspect command.
+// Build with the nomssql tag to leave it out.
+import _ "github.com/denisenkom/go-mssqldb"
@@ -0,0 +1,8 @@
+//go:build !nomysql
+// +build !nomysql
+
+package main
+
+// The MySQL driver is registered as "mysql" for the introspect command.
+// Build with the nomysql tag to leave it out.
+import _ "github.com/go-sql-driver/mysql"
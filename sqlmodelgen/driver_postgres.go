@@ -0,0 +1,8 @@
+//go:build !nopostgres
+// +build !nopostgres
+
+package main
+
+// The PostgreSQL driver is registered as "postgres" for the introspect command.
+// Build with the nopostgres tag to leave it out.
+import _ "github.com/lib/pq"
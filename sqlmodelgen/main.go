@@ -1,16 +1,24 @@
 package main
 
 import (
+	"bytes"
+	goerrors "errors"
+	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"text/template"
 
 	"github.com/davecgh/go-spew/spew"
 
 	"github.com/skillian/argparse"
 	"github.com/skillian/expr/errors"
-	"github.com/skillian/sqlmodel"
 	"github.com/skillian/logging"
+	"github.com/skillian/sqlmodel"
+	"github.com/skillian/sqlmodel/config"
 )
 
 var (
@@ -24,22 +32,328 @@ var (
 	)
 )
 
+// Args holds the parsed command line arguments.  The global arguments
+// are accepted by every command; the rest are only set by the commands
+// that accept them.
 type Args struct {
-	LogLevel     logging.Level
-	ConfigFile   string
-	ModelFile    string
-	ModelContext sqlmodelgen.ModelContext
-	TemplateDir  string
+	LogLevel         logging.Level
+	ModelContextName string
+	ModelContext     sqlmodelgen.ModelContext
+	TemplateDir      string
+	Namespace        string
+
+	ConfigFile string
+	ModelFile  string
+	Split      bool
+
+	// Check compares the generated model with the existing output
+	// files instead of writing them.
+	Check bool
+
+	// Watch regenerates the model whenever its configuration or
+	// templates change, polling them every PollInterval.
+	Watch        bool
+	PollInterval string
+
+	// OtherConfigFile is the configuration file that diff compares
+	// ConfigFile to.
+	OtherConfigFile string
+
+	// Driver, DSN, Database and Schemas select the database that
+	// introspect reads.
+	Driver   string
+	DSN      string
+	Database string
+	Schemas  string
+}
+
+const (
+	// exitOK is the exit status of successful commands.
+	exitOK = 0
+
+	// exitFailure is the exit status of commands that fail or, for
+	// check and diff, find problems or differences.
+	exitFailure = 1
+
+	// exitUsage is the exit status of invalid command lines.
+	exitUsage = 2
+)
+
+// errFailed is returned by commands that have already reported why they
+// failed.
+var errFailed = errors.New("failed")
+
+// usageError is an error in the command line.
+type usageError struct{ error }
+
+// command is a sqlmodelgen subcommand.
+type command struct {
+	name string
+	help string
+
+	// addArguments adds the command's own arguments to its parser.
+	addArguments func(p *argparse.ArgumentParser, args *Args)
+
+	run func(args Args) error
+}
+
+var commands = []command{
+	{
+		name: "generate",
+		help: "Generate models from a configuration file (into " +
+			"its targets unless -t/--type is specified)",
+		addArguments: func(p *argparse.ArgumentParser, args *Args) {
+			p.MustAddArgument(
+				argparse.OptionStrings("-s", "--split"),
+				argparse.Action("store_true"),
+				argparse.Help(
+					"Write one file per type into the "+
+						"modelfile directory",
+				),
+			).MustBind(&args.Split)
+			p.MustAddArgument(
+				argparse.OptionStrings("-w", "--watch"),
+				argparse.Action("store_true"),
+				argparse.Help(
+					"Keep regenerating the model when "+
+						"its configuration or templates "+
+						"change",
+				),
+			).MustBind(&args.Watch)
+			p.MustAddArgument(
+				argparse.OptionStrings("--check"),
+				argparse.Action("store_true"),
+				argparse.Help(
+					"Write nothing but print a diff of "+
+						"the output files that are out "+
+						"of date and fail if there are "+
+						"any",
+				),
+			).MustBind(&args.Check)
+			p.MustAddArgument(
+				argparse.OptionStrings("--poll-interval"),
+				argparse.Action("store"),
+				argparse.Default("1s"),
+				argparse.Help(
+					"How often --watch checks for "+
+						"changes (default: %v)",
+					"1s",
+				),
+			).MustBind(&args.PollInterval)
+			addConfigFileArgument(p, args)
+			p.MustAddArgument(
+				argparse.Dest("modelfile"),
+				argparse.Action("store"),
+				argparse.Default(""),
+				argparse.Help(
+					"output model file (default: "+
+						"standard output)",
+				),
+			).MustBind(&args.ModelFile)
+		},
+		run: Main,
+	},
+	{
+		name: "check",
+		help: "Check that a configuration file is valid and that " +
+			"models can be generated from it (by its targets or " +
+			"every model context unless -t/--type is specified)",
+		addArguments: addConfigFileArgument,
+		run:          runCheck,
+	},
+	{
+		name: "introspect",
+		help: "Write the configuration of an existing database's " +
+			"tables, read from its INFORMATION_SCHEMA views",
+		addArguments: addIntrospectArguments,
+		run:          runIntrospect,
+	},
+	{
+		name: "diff",
+		help: "Report the databases, schemas, tables, views and " +
+			"columns that differ between two configuration files",
+		addArguments: func(p *argparse.ArgumentParser, args *Args) {
+			addConfigFileArgument(p, args)
+			p.MustAddArgument(
+				argparse.Dest("otherconfigfile"),
+				argparse.Action("store"),
+				argparse.Required,
+				argparse.Help(
+					"configuration file compared to "+
+						"configfile",
+				),
+			).MustBind(&args.OtherConfigFile)
+		},
+		run: runDiff,
+	},
+	{
+		name:         "dump-model",
+		help:         "Dump the model built from a configuration file",
+		addArguments: addConfigFileArgument,
+		run:          runDumpModel,
+	},
+	{
+		name: "schema",
+		help: "Write the JSON Schema of configuration files for " +
+			"editors to complete and validate them with",
+		addArguments: func(p *argparse.ArgumentParser, args *Args) {
+			p.MustAddArgument(
+				argparse.Dest("schemafile"),
+				argparse.Action("store"),
+				argparse.Default(""),
+				argparse.Help(
+					"output JSON Schema file (default: "+
+						"standard output)",
+				),
+			).MustBind(&args.ModelFile)
+		},
+		run: runSchema,
+	},
+	{
+		name: "list-contexts",
+		help: "List the names of the model contexts that can be " +
+			"passed to -t/--type",
+		run: runListContexts,
+	},
 }
 
 func main() {
-	var args Args
+	os.Exit(run(os.Args[1:]))
+}
+
+// run runs the command in argv and returns the process' exit status.
+func run(argv []string) int {
+	if len(argv) == 0 {
+		printUsage(os.Stderr)
+		return exitUsage
+	}
+	switch argv[0] {
+	case "-h", "--help", "help":
+		printUsage(os.Stdout)
+		return exitOK
+	}
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == argv[0] {
+			cmd = &commands[i]
+			break
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "%s: unknown command %q\n\n", prog, argv[0])
+		printUsage(os.Stderr)
+		return exitUsage
+	}
+	args := Args{LogLevel: logging.WarnLevel}
 	parser := argparse.MustNewArgumentParser(
-		argparse.Description(
-			"Generate models from SQL definitions",
-		),
+		argparse.Prog(prog+" "+cmd.name),
+		argparse.Description(cmd.help),
 	)
-	parser.MustAddArgument(
+	addGlobalArguments(parser, &args)
+	if cmd.addArguments != nil {
+		cmd.addArguments(parser, &args)
+	}
+	if wantsHelp(argv[1:]) {
+		help, err := parser.FormatHelp()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s: %s\n", prog, cmd.name, errorMessage(err))
+			return exitFailure
+		}
+		fmt.Fprintln(os.Stdout, help)
+		return exitOK
+	}
+	if err := parseArgs(parser, argv[1:]); err != nil {
+		fmt.Fprintf(
+			os.Stderr, "%s %s: %s\n", prog, cmd.name,
+			errorMessage(err),
+		)
+		return exitUsage
+	}
+	logger.SetLevel(args.LogLevel)
+	args.ModelContext, _ = sqlmodelgen.ModelContextByName(args.ModelContextName)
+	err := cmd.run(args)
+	switch {
+	case err == nil:
+		return exitOK
+	case goerrors.Is(err, errFailed):
+		return exitFailure
+	}
+	msg := errorMessage(err)
+	if args.LogLevel <= logging.DebugLevel {
+		msg = err.Error()
+	}
+	fmt.Fprintf(os.Stderr, "%s %s: %s\n", prog, cmd.name, msg)
+	if goerrors.As(err, new(usageError)) {
+		return exitUsage
+	}
+	return exitFailure
+}
+
+var prog = filepath.Base(os.Args[0])
+
+func printUsage(w io.Writer) {
+	fmt.Fprintf(
+		w, "usage: %s <command> [options] [arguments]\n\n"+
+			"Generate models from SQL definitions\n\n"+
+			"commands:\n",
+		prog,
+	)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-15s %s\n", cmd.name, cmd.help)
+	}
+	fmt.Fprintf(
+		w, "\nRun \"%s <command> --help\" for the command's "+
+			"options.\n",
+		prog,
+	)
+}
+
+// wantsHelp checks if the command's arguments ask for its help.  The
+// ArgumentParser handles -h and --help itself, but it prints the help to
+// standard error and exits with status 1.
+func wantsHelp(argv []string) bool {
+	for _, arg := range argv {
+		switch arg {
+		case "-h", "--help":
+			return true
+		case "--":
+			return false
+		}
+	}
+	return false
+}
+
+// parseArgs parses the command's arguments.  ArgumentParser.ParseArgs
+// parses os.Args when it gets no arguments, so when there are none, only
+// the required arguments are checked.
+func parseArgs(p *argparse.ArgumentParser, argv []string) error {
+	if len(argv) > 0 {
+		_, err := p.ParseArgs(argv...)
+		return err
+	}
+	for _, a := range p.Positionals {
+		if a.Required {
+			return errors.Errorf1(
+				"missing required argument %q", a.Dest,
+			)
+		}
+	}
+	return nil
+}
+
+// errorMessage formats err without the call stacks that the errors
+// packages include in their messages.
+func errorMessage(err error) string {
+	msg := errorFrameRegexp.ReplaceAllString(err.Error(), "")
+	return strings.ReplaceAll(strings.TrimSpace(msg), "\n\n", ": ")
+}
+
+var errorFrameRegexp = regexp.MustCompile(
+	`\n[^\n]+\n\t[^\n]+:\d+ \+[0-9a-f]+|\n +at [^\n]+`,
+)
+
+func addGlobalArguments(p *argparse.ArgumentParser, args *Args) {
+	p.MustAddArgument(
 		argparse.OptionStrings("--log-level"),
 		argparse.Action("store"),
 		argparse.Choices(
@@ -55,121 +369,188 @@ func main() {
 			"warn",
 		),
 	).MustBind(&args.LogLevel)
-	parser.MustAddArgument(
+	names := sqlmodelgen.ModelContextNames()
+	choices := make([]argparse.Choice, len(names))
+	for i, name := range names {
+		choices[i] = argparse.Choice{Key: name, Value: name}
+	}
+	p.MustAddArgument(
 		argparse.OptionStrings("-t", "--type"),
 		argparse.Action("store"),
-		argparse.Choices(
-			argparse.Choice{
-				Key:   "cs",
-				Value: sqlmodelgen.CSModelContext,
-			},
-			argparse.Choice{
-				Key:   "go",
-				Value: sqlmodelgen.GoModelContext,
-			},
-			argparse.Choice{
-				Key:   "wvace",
-				Value: sqlmodelgen.WVAceModelContext,
-			},
+		argparse.Choices(choices...),
+		argparse.Default(""),
+		argparse.Help(
+			"Model context that generates the output (see "+
+				"list-contexts)",
 		),
-	).MustBind(&args.ModelContext)
-	parser.MustAddArgument(
+	).MustBind(&args.ModelContextName)
+	p.MustAddArgument(
 		argparse.OptionStrings("-T", "--template-dir"),
 		argparse.Action("store"),
 		argparse.Default(""),
 		argparse.Help(
-			"Optional custom template directory",
+			"Optional directory of templates that override "+
+				"the model context's templates of the "+
+				"same names.  The overridden templates "+
+				"can be called with a \"base/\" prefix",
 		),
 	).MustBind(&args.TemplateDir)
-	parser.MustAddArgument(
+	p.MustAddArgument(
+		argparse.OptionStrings("-n", "--namespace"),
+		argparse.Action("store"),
+		argparse.Default(""),
+		argparse.Help(
+			"Namespace of models generated from a WorkView "+
+				"ACE workbook",
+		),
+	).MustBind(&args.Namespace)
+}
+
+func addConfigFileArgument(p *argparse.ArgumentParser, args *Args) {
+	p.MustAddArgument(
 		argparse.Dest("configfile"),
 		argparse.Action("store"),
+		argparse.Required,
 		argparse.Help(
 			"configuration file from which the model is "+
-				"derived",
+				"derived: JSON, YAML (.yaml, .yml), TOML "+
+				"(.toml) or a WorkView ACE workbook (.xlsx)",
 		),
 	).MustBind(&args.ConfigFile)
+}
 
-	parser.MustAddArgument(
-		argparse.Dest("modelfile"),
-		argparse.Action("store"),
-		argparse.Help("output model file"),
-	).MustBind(&args.ModelFile)
-
-	parser.MustParseArgs()
-
-	if err := Main(args); err != nil {
-		panic(err)
+// loadConfig reads the configuration file, name, and the files that it
+// includes and gets the files that were read.
+func loadConfig(args Args, name string) (*config.Config, sqlmodelgen.ConfigFiles, error) {
+	j, files, err := sqlmodelgen.LoadConfig(name)
+	if err != nil {
+		return nil, files, err
+	}
+	if strings.EqualFold(filepath.Ext(name), ".xlsx") {
+		j.Namespace = args.Namespace
 	}
+	return j, files, nil
 }
 
-func Main(args Args) (Err error) {
-	logger.SetLevel(args.LogLevel)
-	f, err := os.Open(args.ConfigFile)
+// buildConfig reads the ConfigFile and builds its model for the
+// ModelContext.
+func buildConfig(args Args) (*sqlmodelgen.Config, error) {
+	j, files, err := loadConfig(args, args.ConfigFile)
 	if err != nil {
-		return errors.Errorf1From(
-			err, "failed to open config file %q",
-			args.ConfigFile,
-		)
+		return nil, err
 	}
-	defer errors.Catch(&Err, f.Close)
-	cfg, err := sqlmodelgen.ConfigFromJSON(f, args.ModelContext)
+	return newConfig(target{args: args, config: j, files: files})
+}
+
+// newConfig builds the target configuration's model.  Errors in the
+// configuration are prefixed with the positions of their values.
+func newConfig(t target) (*sqlmodelgen.Config, error) {
+	cfg, err := sqlmodelgen.NewConfig(t.config, t.args.ModelContext)
 	if err != nil {
-		return errors.Errorf1From(
-			err, "failed to parse file %v as JSON",
-			args.ConfigFile,
-		)
-	}
-	var out io.WriteCloser
-	if args.ModelFile == "" {
-		out = nopWriteCloser{os.Stdout}
-	} else {
-		out, err = os.Create(args.ModelFile)
-		if err != nil {
-			return errors.Errorf1From(
-				err, "failed to create output file: %v",
-				args.ModelFile,
-			)
+		if pos, ok := t.position(err); ok {
+			return nil, errors.Errorf1From(err, "%v", pos)
 		}
+		return nil, errors.Errorf1From(
+			err, "failed to initialize configuration from %v",
+			t.args.ConfigFile,
+		)
 	}
 	if logger.Level() <= logging.VerboseLevel {
 		logger.Verbose("configuration:\n\n%v", spew.Sdump(cfg))
 	}
-	defer errors.Catch(&Err, out.Close)
-	switch mc := args.ModelContext.(type) {
-	case sqlmodelgen.TemplateContext:
-		fm := make(template.FuncMap, 8)
-		t := sqlmodelgen.AddFuncs(
-			template.New("<sqlmodelgen>"), fm, args.ModelContext,
-		).Funcs(fm)
-		if args.TemplateDir == "" {
-			fsys := mc.FS()
-			t, err = t.ParseFS(fsys, "*.txt")
-			if err != nil {
-				return errors.Errorf1From(
-					err, "failed to parse ModelContext file "+
-						"system: %v",
-					fsys,
-				)
-			}
-		} else {
-			t, err = t.ParseFiles(args.TemplateDir, "*.txt")
-			if err != nil {
-				return errors.Errorf1From(
-					err, "failed to parse template directory: %v",
-					args.TemplateDir,
+	return cfg, nil
+}
+
+// requireModelContext returns a usageError if no ModelContext was
+// specified.
+func requireModelContext(args Args) error {
+	if args.ModelContext == nil {
+		return usageError{errors.Errorf0(
+			"a model context must be specified with -t/--type",
+		)}
+	}
+	return nil
+}
+
+// Main generates the model for the ModelContext or, if none was
+// specified, the configuration's Targets.
+func Main(args Args) error {
+	if args.Watch {
+		if args.Check {
+			return usageError{errors.Errorf0(
+				"--check and --watch cannot be combined",
+			)}
+		}
+		return watch(args)
+	}
+	j, files, err := loadConfig(args, args.ConfigFile)
+	if err != nil {
+		return err
+	}
+	if args.ModelContext != nil {
+		return generate(target{args: args, config: j, files: files})
+	}
+	ts, err := configTargets(args, j, files)
+	if err != nil {
+		return err
+	}
+	return reportTargetErrors(args, ts, generateTargets(ts))
+}
+
+// generate generates the target's model.  The output is rendered into
+// memory before any of it is written and only the files whose content
+// changes are written.
+func generate(t target) error {
+	cfg, err := newConfig(t)
+	if err != nil {
+		return err
+	}
+	args := t.args
+	if args.Check {
+		return checkOutputs(args, cfg)
+	}
+	ofs, err := renderOutputs(args, cfg)
+	if err != nil {
+		return err
+	}
+	for _, of := range ofs {
+		if of.name == "" {
+			if _, err = os.Stdout.Write(of.data); err != nil {
+				return errors.Errorf0From(
+					err, "failed to write output to "+
+						"standard output",
 				)
 			}
+			continue
+		}
+		if err = writeFileIfChanged(of.name, of.data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeModel writes the ModelContext's output into w.
+func writeModel(w io.Writer, args Args, cfg *sqlmodelgen.Config) error {
+	switch mc := args.ModelContext.(type) {
+	case sqlmodelgen.TemplateContext:
+		t, err := parseTemplates(args, mc)
+		if err != nil {
+			return err
 		}
-		if err = t.ExecuteTemplate(out, "0root.txt", cfg); err != nil {
+		src, err := render(t, args.ModelContext, "0root.txt", cfg)
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write(src); err != nil {
 			return errors.Errorf1From(
-				err, "error executing template: %v", t,
+				err, "failed to write output to %v", w,
 			)
 		}
 		return nil
 
 	case sqlmodelgen.ModelWriter:
-		if err = mc.WriteModel(out, cfg); err != nil {
+		if err := mc.WriteModel(w, cfg); err != nil {
 			return errors.Errorf1From(
 				err, "error executing model writer: %[1]v "+
 					"(type: %[1]T)",
@@ -184,6 +565,287 @@ func Main(args Args) (Err error) {
 	)
 }
 
-type nopWriteCloser struct{ io.Writer }
+// parseTemplates parses the ModelContext's templates.  Templates in the
+// template directory override the ModelContext's templates of the same
+// name.  The ModelContext's templates remain available with a "base/"
+// prefix so that overrides can call the templates that they replace.
+func parseTemplates(args Args, mc sqlmodelgen.TemplateContext) (*template.Template, error) {
+	fm := make(template.FuncMap, 8)
+	t := sqlmodelgen.AddFuncs(
+		template.New("<sqlmodelgen>"), fm, args.ModelContext,
+	).Funcs(fm)
+	fsys := mc.FS()
+	t, err := t.ParseFS(fsys, "*.txt")
+	if err != nil {
+		return nil, errors.Errorf1From(
+			err, "failed to parse ModelContext file "+
+				"system: %v",
+			fsys,
+		)
+	}
+	if args.TemplateDir == "" {
+		return t, nil
+	}
+	if _, err = os.Stat(args.TemplateDir); err != nil {
+		return nil, errors.Errorf1From(
+			err, "failed to open template directory: %v",
+			args.TemplateDir,
+		)
+	}
+	dir := os.DirFS(args.TemplateDir)
+	names, err := fs.Glob(dir, "*.txt")
+	if err == nil && len(names) == 0 {
+		err = errors.Errorf0("no *.txt templates found")
+	}
+	if err != nil {
+		return nil, errors.Errorf1From(
+			err, "failed to parse template directory: %v",
+			args.TemplateDir,
+		)
+	}
+	// The template directory's files hide the ModelContext's files
+	// with the same names, which stay reachable under base/.
+	overlay := sqlmodelgen.OverlayFS(
+		dir, sqlmodelgen.PrefixFS(baseTemplateDir, fsys), fsys,
+	)
+	if t, err = parseBaseTemplates(t, fm, overlay); err != nil {
+		return nil, errors.Errorf1From(
+			err, "failed to parse ModelContext file "+
+				"system: %v",
+			fsys,
+		)
+	}
+	// The template directory's files are parsed last so that their
+	// {{define}}s replace the ModelContext's.
+	if t, err = t.ParseFS(overlay, names...); err != nil {
+		return nil, errors.Errorf1From(
+			err, "failed to parse template directory: %v",
+			args.TemplateDir,
+		)
+	}
+	return t, nil
+}
+
+// parseBaseTemplates adds the templates in the base directory of fsys
+// to t.  The names of the files' templates include the directory and so
+// do the names of the templates that the files {{define}}.
+func parseBaseTemplates(t *template.Template, fm template.FuncMap, fsys fs.FS) (*template.Template, error) {
+	bases, err := fs.Glob(fsys, baseTemplateDir+"/*.txt")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range bases {
+		bs, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		bt, err := template.New(name).Funcs(fm).Parse(string(bs))
+		if err != nil {
+			return nil, err
+		}
+		for _, dt := range bt.Templates() {
+			if dt.Tree == nil {
+				continue
+			}
+			dname := dt.Name()
+			if dname != name {
+				dname = baseTemplateDir + "/" + dname
+			}
+			if _, err = t.AddParseTree(dname, dt.Tree); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return t, nil
+}
+
+// baseTemplateDir is the directory of the template overlay that holds
+// the ModelContext's templates, so that a template directory's templates
+// can execute the templates they override as "base/<name>".
+const baseTemplateDir = "base"
+
+// render executes the named template and formats its output if the
+// ModelContext is an OutputFormatter.
+func render(t *template.Template, mc sqlmodelgen.ModelContext, name string, data interface{}) ([]byte, error) {
+	buf := bytes.Buffer{}
+	if err := t.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, errors.Errorf1From(
+			err, "error executing template %q", name,
+		)
+	}
+	src := buf.Bytes()
+	if f, ok := mc.(sqlmodelgen.OutputFormatter); ok {
+		var err error
+		if src, err = f.FormatOutput(src); err != nil {
+			return nil, errors.Errorf0From(
+				err, "error formatting template output",
+			)
+		}
+	}
+	return src, nil
+}
+
+// outputFile is a file of the ModelContext's output rendered into
+// memory.
+type outputFile struct {
+	name string
+	data []byte
+}
+
+// renderOutputs renders the ModelContext's output files.  Unless the
+// output is split, the one file's name is the ModelFile, which is empty
+// for standard output.
+func renderOutputs(args Args, cfg *sqlmodelgen.Config) ([]outputFile, error) {
+	if args.Split {
+		return renderSplit(args, cfg)
+	}
+	var buf bytes.Buffer
+	if err := writeModel(&buf, args, cfg); err != nil {
+		return nil, err
+	}
+	return []outputFile{{name: args.ModelFile, data: buf.Bytes()}}, nil
+}
 
-func (n nopWriteCloser) Close() error { return nil }
+// writeFileIfChanged writes data into the named file unless the file
+// already holds it.  The data is written into a temporary file that then
+// replaces the named file so that it is never partially written.
+func writeFileIfChanged(name string, data []byte) (Err error) {
+	old, err := os.ReadFile(name)
+	if err == nil && bytes.Equal(old, data) {
+		logger.Verbose1("%v is unchanged", name)
+		return nil
+	}
+	var mode fs.FileMode = 0644
+	if fi, err := os.Stat(name); err == nil {
+		mode = fi.Mode().Perm()
+	}
+	dir := filepath.Dir(name)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return errors.Errorf1From(
+			err, "failed to create directory of %v", name,
+		)
+	}
+	f, err := os.CreateTemp(dir, "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return errors.Errorf1From(
+			err, "failed to create temporary file for %v", name,
+		)
+	}
+	defer func() {
+		if Err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+	if _, err = f.Write(data); err != nil {
+		return errors.Errorf1From(
+			err, "failed to write output to %v", f.Name(),
+		)
+	}
+	if err = f.Chmod(mode); err != nil {
+		return errors.Errorf1From(
+			err, "failed to set the mode of %v", f.Name(),
+		)
+	}
+	if err = f.Close(); err != nil {
+		return errors.Errorf1From(
+			err, "failed to write output to %v", f.Name(),
+		)
+	}
+	if err = os.Rename(f.Name(), name); err != nil {
+		return errors.Errorf2From(
+			err, "failed to replace %v with %v", name, f.Name(),
+		)
+	}
+	logger.Info1("wrote %v", name)
+	return nil
+}
+
+// renderSplit renders the files of the ModelContext's split output.
+// Their names are joined to the ModelFile directory.
+func renderSplit(args Args, cfg *sqlmodelgen.Config) ([]outputFile, error) {
+	if args.ModelFile == "" {
+		return nil, errors.Errorf0(
+			"split output requires a modelfile directory",
+		)
+	}
+	mfs, err := splitModel(args, cfg)
+	if err != nil {
+		return nil, err
+	}
+	paths := make(map[string]struct{}, len(mfs))
+	for _, mf := range mfs {
+		key := strings.ToLower(mf.Path)
+		if _, ok := paths[key]; ok {
+			return nil, errors.Errorf1(
+				"multiple types would be written to %q",
+				mf.Path,
+			)
+		}
+		paths[key] = struct{}{}
+	}
+	ofs := make([]outputFile, len(mfs))
+	for i, mf := range mfs {
+		var buf bytes.Buffer
+		if err := mf.Write(&buf); err != nil {
+			return nil, errors.Errorf1From(
+				err, "failed to generate %v", mf.Path,
+			)
+		}
+		ofs[i] = outputFile{
+			name: filepath.Join(args.ModelFile, filepath.FromSlash(mf.Path)),
+			data: buf.Bytes(),
+		}
+	}
+	return ofs, nil
+}
+
+// splitModel gets the files of the ModelContext's split output.
+func splitModel(args Args, cfg *sqlmodelgen.Config) ([]sqlmodelgen.ModelFile, error) {
+	switch mc := args.ModelContext.(type) {
+	case sqlmodelgen.ModelSplitter:
+		mfs, err := mc.SplitModel(cfg)
+		if err != nil {
+			return nil, errors.Errorf0From(
+				err, "failed to split output into files",
+			)
+		}
+		return mfs, nil
+	case sqlmodelgen.FileSplitter:
+		tc, ok := args.ModelContext.(sqlmodelgen.TemplateContext)
+		if !ok {
+			break
+		}
+		t, err := parseTemplates(args, tc)
+		if err != nil {
+			return nil, err
+		}
+		ofs, err := mc.SplitFiles(cfg)
+		if err != nil {
+			return nil, errors.Errorf0From(
+				err, "failed to split output into files",
+			)
+		}
+		mfs := make([]sqlmodelgen.ModelFile, len(ofs))
+		for i, of := range ofs {
+			of := of
+			mfs[i] = sqlmodelgen.ModelFile{
+				Path: of.Path,
+				Write: func(w io.Writer) error {
+					src, err := render(t, args.ModelContext, of.Template, of.Data)
+					if err != nil {
+						return err
+					}
+					_, err = w.Write(src)
+					return err
+				},
+			}
+		}
+		return mfs, nil
+	}
+	return nil, errors.Errorf1(
+		"model context %[1]v (type: %[1]T) cannot split "+
+			"its output into multiple files",
+		args.ModelContext,
+	)
+}
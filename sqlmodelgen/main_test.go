@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skillian/sqlmodel"
+)
+
+func TestRunExitStatus(t *testing.T) {
+	stdout, stderr := os.Stdout, os.Stderr
+	null, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer null.Close()
+	os.Stdout, os.Stderr = null, null
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+	tests := []struct {
+		argv []string
+		want int
+	}{
+		{[]string{"--help"}, exitOK},
+		{[]string{"generate", "--help"}, exitOK},
+		{[]string{"introspect", "-h"}, exitOK},
+		{[]string{"check", "config.json", "--help"}, exitOK},
+		{nil, exitUsage},
+		{[]string{"bogus"}, exitUsage},
+		{[]string{"generate"}, exitUsage},
+	}
+	for _, tc := range tests {
+		if got := run(tc.argv); got != tc.want {
+			t.Errorf("%q exited with %d, want %d", tc.argv, got, tc.want)
+		}
+	}
+}
+
+// TestTemplateDir overrides a C# template file and a template that
+// another file defines, and calls the templates that they override.
+func TestTemplateDir(t *testing.T) {
+	dir := t.TempDir()
+	templates := map[string]string{
+		"namespace.txt": `Custom.{{template "base/namespace.txt" .}}`,
+		"property.txt": "{{define \"csproperty\"}}\t\t// {{.Name}}\r\n" +
+			`{{template "base/csproperty" .}}{{end}}`,
+	}
+	for name, text := range templates {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	j, _, err := sqlmodelgen.LoadConfig(filepath.Join("testdata", "models.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := sqlmodelgen.NewConfig(j, sqlmodelgen.CSModelContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ofs, err := renderOutputs(Args{
+		ModelContext: sqlmodelgen.CSModelContext,
+		TemplateDir:  dir,
+	}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(ofs[0].data)
+	for _, want := range []string{
+		"global::Custom.models.Court.dbo.Party",
+		"\t\t// Note\r\n\t\tpublic string Note { get; set; }",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
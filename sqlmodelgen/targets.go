@@ -0,0 +1,175 @@
+package main
+
+import (
+	goerrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/sqlmodel"
+	"github.com/skillian/sqlmodel/config"
+)
+
+// target is a config.Target resolved into the arguments and
+// configuration that generate it.
+type target struct {
+	name   string
+	args   Args
+	config *config.Config
+
+	// files are the files that the configuration was loaded from.
+	files sqlmodelgen.ConfigFiles
+
+	// overrides are the paths of the target's options that override
+	// the configuration's options by the names of those options.
+	overrides map[string][]string
+}
+
+// position gets the position of the configuration value that caused
+// err if err wraps a *sqlmodelgen.ConfigError.
+func (t target) position(err error) (sqlmodelgen.ConfigPos, bool) {
+	var ce *sqlmodelgen.ConfigError
+	if !goerrors.As(err, &ce) || len(ce.Path) == 0 {
+		return sqlmodelgen.ConfigPos{}, false
+	}
+	path := ce.Path
+	if p, ok := t.overrides[path[0]]; ok {
+		path = append(append([]string(nil), p...), path[1:]...)
+	}
+	return t.files.Position(path...)
+}
+
+// resolveTargets resolves the configuration's Targets.  The returned
+// configurations are shallow copies of j with the targets' options
+// applied.
+func resolveTargets(args Args, j *config.Config, files sqlmodelgen.ConfigFiles) ([]target, error) {
+	dir := filepath.Dir(args.ConfigFile)
+	resolve := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(dir, filepath.FromSlash(path))
+	}
+	ts := make([]target, len(j.Targets))
+	outputs := make(map[string]string, len(j.Targets))
+	for i, t := range j.Targets {
+		name := fmt.Sprintf("target %d (%s)", i, t.Context)
+		path := []string{"Targets", strconv.Itoa(i)}
+		pos, _ := files.Position(path...)
+		mc, ok := sqlmodelgen.ModelContextByName(t.Context)
+		if !ok {
+			cpos, _ := files.Position(append(path, "Context")...)
+			return nil, errors.Errorf3(
+				"%v: %s: unknown model context %q (see "+
+					"list-contexts)",
+				cpos, name, t.Context,
+			)
+		}
+		if t.Output == "" {
+			return nil, errors.Errorf2("%v: %s has no Output", pos, name)
+		}
+		targs := args
+		targs.ModelContextName = t.Context
+		targs.ModelContext = mc
+		targs.TemplateDir = resolve(t.TemplateDir)
+		targs.ModelFile = resolve(t.Output)
+		targs.Split = t.Split
+		key := strings.ToLower(filepath.Clean(targs.ModelFile))
+		if other, ok := outputs[key]; ok {
+			opos, _ := files.Position(append(path, "Output")...)
+			return nil, errors.Errorf(
+				"%v: %s and %s are both written to %v",
+				opos, other, name, targs.ModelFile,
+			)
+		}
+		outputs[key] = name
+		tj := *j
+		tj.Targets = nil
+		overrides := make(map[string][]string, 4)
+		if t.Namespace != "" {
+			tj.Namespace = t.Namespace
+			overrides["Namespace"] = append(path, "Namespace")
+		}
+		if t.Go != nil {
+			tj.Go = *t.Go
+			overrides["Go"] = append(path, "Go")
+		}
+		if t.CS != nil {
+			tj.CS = *t.CS
+			overrides["CS"] = append(path, "CS")
+		}
+		if t.WorkView != nil {
+			tj.WorkView = *t.WorkView
+			overrides["WorkView"] = append(path, "WorkView")
+		}
+		ts[i] = target{
+			name:      name,
+			args:      targs,
+			config:    &tj,
+			files:     files,
+			overrides: overrides,
+		}
+	}
+	return ts, nil
+}
+
+// configTargets resolves the configuration's Targets when no
+// ModelContext was specified.
+func configTargets(args Args, j *config.Config, files sqlmodelgen.ConfigFiles) ([]target, error) {
+	if len(j.Targets) == 0 {
+		return nil, usageError{errors.Errorf0(
+			"a model context must be specified with -t/--type " +
+				"or the configuration's Targets",
+		)}
+	}
+	if args.ModelFile != "" || args.Split {
+		return nil, usageError{errors.Errorf0(
+			"a modelfile and -s/--split require -t/--type",
+		)}
+	}
+	return resolveTargets(args, j, files)
+}
+
+// generateTargets generates the targets concurrently and returns their
+// errors.  Every target is generated even if others fail.
+func generateTargets(ts []target) []error {
+	errs := make([]error, len(ts))
+	var wg sync.WaitGroup
+	for i := range ts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = generate(ts[i])
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+// reportTargetErrors writes the targets' errors to standard error and
+// returns errFailed if there are any.  errFailed itself has already been
+// reported.
+func reportTargetErrors(args Args, ts []target, errs []error) error {
+	failed := false
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		failed = true
+		if goerrors.Is(err, errFailed) {
+			continue
+		}
+		fmt.Fprintf(
+			os.Stderr, "%s: %s: %s\n",
+			args.ConfigFile, ts[i].name, errorMessage(err),
+		)
+	}
+	if failed {
+		return errFailed
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package models
+
+// These tests are run against the Go models generated from models.json
+// by TestGoModelsCompile.
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCompositeKeyModelJSON(t *testing.T) {
+	m := DocketParty{
+		DocketPartyKey: DocketPartyKey{
+			DocketID: DocketID{Raw: "d1"},
+			PartyID:  PartyID{Raw: 7},
+			Seq:      Seq{Raw: 2},
+		},
+		Note: "hello",
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got DocketParty
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, m) {
+		t.Errorf("%s was unmarshaled into %+v, want %+v", data, got, m)
+	}
+	// the key's methods must not be promoted to the model.
+	var v interface{} = m
+	if _, ok := v.(driver.Valuer); ok {
+		t.Errorf("%T is a driver.Valuer", m)
+	}
+	if _, ok := v.(json.Marshaler); ok {
+		t.Errorf("%T is a json.Marshaler", m)
+	}
+}
+
+func TestKeyText(t *testing.T) {
+	key := DocketPartyKey{DocketID{Raw: "d1"}, PartyID{Raw: 7}, Seq{Raw: 2}}
+	v, err := key.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got DocketPartyKey
+	if err := got.Scan(v); err != nil {
+		t.Fatal(err)
+	}
+	if got != key {
+		t.Errorf("%v was scanned into %+v, want %+v", v, got, key)
+	}
+}
+
+func TestIDs(t *testing.T) {
+	var (
+		_ sql.Scanner   = (*PartyID)(nil)
+		_ driver.Valuer = PartyID{}
+	)
+	if _, err := (PartyID{Raw: math.MaxUint64}).Value(); err == nil {
+		t.Error("PartyID above math.MaxInt64 was valued")
+	}
+	var id PartyID
+	if err := id.Scan([]byte("18446744073709551615")); err != nil || id.Raw != math.MaxUint64 {
+		t.Errorf("scanned %v, %v", id, err)
+	}
+	if err := id.Scan(int64(-1)); err == nil {
+		t.Error("negative PartyID was scanned")
+	}
+	if err := id.Scan(nil); err == nil {
+		t.Error("NULL was scanned into a PartyID")
+	}
+	var seq Seq
+	if err := seq.Scan(int64(math.MaxInt16 + 1)); err == nil {
+		t.Error("out of range Seq was scanned")
+	}
+	if v, err := (Seq{Raw: 3}).Value(); err != nil || v != int64(3) {
+		t.Errorf("Seq was valued as %v, %v", v, err)
+	}
+}
+
+func TestNullableFK(t *testing.T) {
+	var d Docket
+	i := -1
+	for j, name := range d.AppendNames(nil) {
+		if name == "LeadPartyID" {
+			i = j
+		}
+	}
+	if i == -1 {
+		t.Fatal("Docket has no LeadPartyID field")
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m["LeadPartyID"]; ok {
+		t.Errorf("NULL LeadPartyID was marshaled into %s", data)
+	}
+	if vs := d.AppendValues(nil); vs[i] != nil {
+		t.Errorf("NULL LeadPartyID was valued as %v", vs[i])
+	}
+	// the db is not queried for NULL references.
+	p, err := d.PartyByLeadPartyID(context.Background(), nil)
+	if p != nil || err != nil {
+		t.Errorf("got %v, %v, want nil, nil", p, err)
+	}
+	fs := d.AppendFields(nil)
+	if _, ok := fs[i].(**PartyID); !ok {
+		t.Errorf("LeadPartyID is scanned into a %T, not a **PartyID", fs[i])
+	}
+	d.LeadPartyID = &PartyID{Raw: 7}
+	if data, err = json.Marshal(d); err != nil {
+		t.Fatal(err)
+	}
+	var got Docket
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.LeadPartyID == nil || *got.LeadPartyID != *d.LeadPartyID {
+		t.Errorf("%s was unmarshaled into %+v", data, got)
+	}
+	if vs := d.AppendValues(nil); vs[i] != uint64(7) {
+		t.Errorf("LeadPartyID was valued as %#v", vs[i])
+	}
+}
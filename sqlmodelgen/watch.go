@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/sqlmodel"
+)
+
+// watch generates the model and then regenerates the targets affected
+// by changes to their configuration or template files until the process
+// is interrupted.  Errors are reported without stopping.
+func watch(args Args) error {
+	interval, err := time.ParseDuration(args.PollInterval)
+	if err != nil || interval <= 0 {
+		return usageError{errors.Errorf1(
+			"invalid poll interval: %q", args.PollInterval,
+		)}
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	w := watcher{args: args}
+	for {
+		w.poll()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+type watcher struct {
+	args Args
+
+	// configFiles are the files that the configuration was last
+	// loaded from and configStamps are their stamps.
+	configFiles  sqlmodelgen.ConfigFiles
+	configStamps fileStamps
+
+	targets []target
+
+	// templateStamps are the stamps of each target's templates when
+	// it was last generated.
+	templateStamps []fileStamps
+}
+
+// poll regenerates the targets whose configuration or templates changed
+// since the last poll.
+func (w *watcher) poll() {
+	dirty := make([]bool, len(w.targets))
+	if w.configStamps == nil || !w.stampConfig().equal(w.configStamps) {
+		ts, err := w.load()
+		if err != nil {
+			fmt.Fprintf(
+				os.Stderr, "%s: %s\n",
+				w.args.ConfigFile, errorMessage(err),
+			)
+		}
+		w.configStamps = w.stampConfig()
+		w.targets = ts
+		w.templateStamps = make([]fileStamps, len(ts))
+		dirty = make([]bool, len(ts))
+		for i := range dirty {
+			dirty[i] = true
+		}
+	}
+	ts := make([]target, 0, len(w.targets))
+	for i, t := range w.targets {
+		stamps := stampFiles(t.args.TemplateDir)
+		if !stamps.equal(w.templateStamps[i]) {
+			dirty[i] = true
+		}
+		w.templateStamps[i] = stamps
+		if dirty[i] {
+			ts = append(ts, t)
+		}
+	}
+	if len(ts) == 0 {
+		return
+	}
+	errs := generateTargets(ts)
+	for i, err := range errs {
+		if err == nil {
+			fmt.Fprintf(
+				os.Stderr, "%s: %s: generated\n",
+				w.args.ConfigFile, ts[i].name,
+			)
+		}
+	}
+	_ = reportTargetErrors(w.args, ts, errs)
+}
+
+// stampConfig stamps the configuration files and the files that match
+// their includes so that new included files are noticed.
+func (w *watcher) stampConfig() fileStamps {
+	paths := append([]string{w.args.ConfigFile}, w.configFiles.Names...)
+	for _, pattern := range w.configFiles.Includes {
+		names, _ := filepath.Glob(pattern)
+		paths = append(paths, names...)
+	}
+	return stampFiles(paths...)
+}
+
+// load loads the configuration and gets its targets.
+func (w *watcher) load() ([]target, error) {
+	j, files, err := loadConfig(w.args, w.args.ConfigFile)
+	w.configFiles = files
+	if err != nil {
+		return nil, err
+	}
+	if w.args.ModelContext != nil {
+		return []target{{
+			name:   w.args.ModelContextName,
+			args:   w.args,
+			config: j,
+			files:  files,
+		}}, nil
+	}
+	return configTargets(w.args, j, files)
+}
+
+// fileStamp identifies a version of a file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// fileStamps are the stamps of files by their paths.  Missing files
+// have zero stamps so that creating them is a change.
+type fileStamps map[string]fileStamp
+
+// stampFiles stamps the files and the files within the directories.
+// Empty paths are skipped.
+func stampFiles(paths ...string) fileStamps {
+	stamps := make(fileStamps)
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		_ = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				stamps[p] = fileStamp{}
+				return nil
+			}
+			if d.IsDir() {
+				return nil
+			}
+			fi, err := d.Info()
+			if err != nil {
+				stamps[p] = fileStamp{}
+				return nil
+			}
+			stamps[p] = fileStamp{modTime: fi.ModTime(), size: fi.Size()}
+			return nil
+		})
+	}
+	return stamps
+}
+
+func (a fileStamps) equal(b fileStamps) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, sa := range a {
+		sb, ok := b[path]
+		if !ok || sa.size != sb.size || !sa.modTime.Equal(sb.modTime) {
+			return false
+		}
+	}
+	return true
+}
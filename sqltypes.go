@@ -0,0 +1,180 @@
+package sqlmodelgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/expr/stream/sqlstream/sqltypes"
+)
+
+// UintType is the "metatype" of unsigned integer SQL types.
+type UintType struct{ Bits int }
+
+func (t UintType) String() string { return fmt.Sprintf("uint(%d)", t.Bits) }
+
+// GUIDType is the "metatype" of globally unique identifier SQL types
+// (e.g. SQL Server's uniqueidentifier or PostgreSQL's uuid).
+type GUIDType struct{}
+
+func (GUIDType) String() string { return "guid" }
+
+// DurationType is the "metatype" of SQL time interval types.
+type DurationType struct{ Prec time.Duration }
+
+func (t DurationType) String() string {
+	return fmt.Sprintf("duration(prec: %s)", t.Prec)
+}
+
+// ZonedTimeType is the "metatype" of date(time) SQL types that also
+// store a time zone offset (e.g. SQL Server's datetimeoffset or
+// PostgreSQL's timestamptz).
+type ZonedTimeType struct{ sqltypes.TimeType }
+
+func (t ZonedTimeType) String() string {
+	return fmt.Sprintf(
+		"datetz(min: %s, max: %s, prec: %s)",
+		t.Min, t.Max, t.Prec,
+	)
+}
+
+// ParseSQLType extends sqltypes.Parse with the types that it doesn't
+// support:
+//
+//	decimal(scale: 2, prec: 10)
+//	uint(32)
+//	guid
+//	duration(prec: 1ms)
+//	datetz(min: 0001-01-01, max: 9999-12-31, prec: 100ns)
+//
+// Any of them can be wrapped in nullable(...).
+func ParseSQLType(s string) (sqltypes.Type, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "guid" {
+		return GUIDType{}, nil
+	}
+	if inner, ok := sqlTypeArgs(s, "nullable"); ok {
+		t, err := ParseSQLType(inner)
+		if err != nil {
+			return nil, err
+		}
+		return sqltypes.Nullable{t}, nil
+	}
+	if args, ok := sqlTypeArgs(s, "uint"); ok {
+		bits, err := strconv.Atoi(strings.TrimSpace(args))
+		if err != nil {
+			return nil, errors.Errorf1From(
+				err, "failed to parse %q as uint bits", args,
+			)
+		}
+		return UintType{Bits: bits}, nil
+	}
+	if args, ok := sqlTypeArgs(s, "decimal"); ok {
+		m, err := parseSQLTypeKeyValues(args)
+		if err != nil {
+			return nil, err
+		}
+		var dt sqltypes.DecimalType
+		for k, v := range m {
+			var p *int
+			switch k {
+			case "scale":
+				p = &dt.Scale
+			case "prec":
+				p = &dt.Prec
+			default:
+				return nil, errors.Errorf1(
+					"unknown decimal parameter: %q", k,
+				)
+			}
+			if *p, err = strconv.Atoi(v); err != nil {
+				return nil, errors.Errorf2From(
+					err, "failed to parse decimal %s: %q",
+					k, v,
+				)
+			}
+		}
+		if dt.Scale > dt.Prec && dt.Prec != 0 {
+			return nil, errors.Errorf2(
+				"decimal scale %d exceeds its precision %d",
+				dt.Scale, dt.Prec,
+			)
+		}
+		return dt, nil
+	}
+	if args, ok := sqlTypeArgs(s, "duration"); ok {
+		m, err := parseSQLTypeKeyValues(args)
+		if err != nil {
+			return nil, err
+		}
+		var dt DurationType
+		if x, ok := m["prec"]; ok {
+			if dt.Prec, err = time.ParseDuration(x); err != nil {
+				return nil, errors.Errorf1From(
+					err, "failed to parse duration precision %q",
+					x,
+				)
+			}
+		}
+		return dt, nil
+	}
+	if args, ok := sqlTypeArgs(s, "datetz"); ok {
+		t, err := sqltypes.Parse("date(" + args + ")")
+		if err != nil {
+			return nil, err
+		}
+		return ZonedTimeType{t.(sqltypes.TimeType)}, nil
+	}
+	return sqltypes.Parse(s)
+}
+
+// sqlTypeArgs gets the arguments between the parentheses of s if s is
+// of the form name(args).
+func sqlTypeArgs(s, name string) (args string, ok bool) {
+	if !strings.HasPrefix(s, name+"(") || !strings.HasSuffix(s, ")") {
+		return "", false
+	}
+	return s[len(name)+1 : len(s)-1], true
+}
+
+// parseSQLTypeKeyValues parses the simple "key: value, ..." parameters
+// of the types added by ParseSQLType.  Values cannot be quoted.
+func parseSQLTypeKeyValues(s string) (map[string]string, error) {
+	m := make(map[string]string)
+	if strings.TrimSpace(s) == "" {
+		return m, nil
+	}
+	for _, kv := range strings.Split(s, ",") {
+		i := strings.IndexByte(kv, ':')
+		if i == -1 {
+			return nil, errors.Errorf1("invalid key value: %q", kv)
+		}
+		m[strings.TrimSpace(kv[:i])] = strings.TrimSpace(kv[i+1:])
+	}
+	return m, nil
+}
+
+// coreSQLType maps the types added by ParseSQLType to the closest
+// sqltypes.Type that sqlstream understands so that generated code only
+// refers to the sqltypes package.
+func coreSQLType(t sqltypes.Type) sqltypes.Type {
+	switch t := t.(type) {
+	case sqltypes.Nullable:
+		return sqltypes.Nullable{coreSQLType(t[0])}
+	case UintType:
+		bits := t.Bits * 2
+		if bits > 64 {
+			bits = 64
+		}
+		return sqltypes.IntType{Bits: bits}
+	case GUIDType:
+		return sqltypes.StringType{Length: 36}
+	case DurationType:
+		return sqltypes.Int64
+	case ZonedTimeType:
+		return t.TimeType
+	}
+	return t
+}
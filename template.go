@@ -29,6 +29,23 @@ func dict(pairs ...Pair) (d Dict) {
 	return
 }
 
+// plural is added to a template's funcmap with the AddFuncs function
+// to name collections of models.  It only knows the regular English
+// plurals (e.g. Party -> Parties, Address -> Addresses).
+func plural(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 &&
+		!strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return name[:len(name)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"),
+		strings.HasSuffix(lower, "z"), strings.HasSuffix(lower, "ch"),
+		strings.HasSuffix(lower, "sh"):
+		return name + "es"
+	}
+	return name + "s"
+}
+
 // CreateDynTemplate creates a "dyntemplate" function whose
 // template name is parameterized
 func CreateDynTemplate(t *template.Template) (dyntemplate func(name string, data interface{}) (string, error)) {
@@ -56,6 +73,7 @@ func AddFuncs(t *template.Template, m template.FuncMap, mc ModelContext) *templa
 	}
 	add(m, "pair", pair)
 	add(m, "dict", dict)
+	add(m, "plural", plural)
 	if _, ok := m["modeltype"]; !ok {
 		add(m, "modeltype", func(t sqltypes.Type) (name string, err error) {
 			_, name, err = mc.ModelType(t)
@@ -71,5 +89,10 @@ func AddFuncs(t *template.Template, m template.FuncMap, mc ModelContext) *templa
 		_, name, err = mc.ModelType(t)
 		return
 	})
+	if fmr, ok := mc.(FuncMapper); ok {
+		for k, v := range fmr.FuncMap() {
+			add(m, k, v)
+		}
+	}
 	return t
 }
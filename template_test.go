@@ -0,0 +1,21 @@
+package sqlmodelgen
+
+import "testing"
+
+func TestPlural(t *testing.T) {
+	tests := []struct{ name, want string }{
+		{"Party", "Parties"},
+		{"Day", "Days"},
+		{"Address", "Addresses"},
+		{"Box", "Boxes"},
+		{"Batch", "Batches"},
+		{"Wish", "Wishes"},
+		{"Docket", "Dockets"},
+		{"Y", "Ys"},
+	}
+	for _, tc := range tests {
+		if got := plural(tc.name); got != tc.want {
+			t.Errorf("plural(%q) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package sqlmodelgen
+
+import (
+	goerrors "errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/skillian/expr/errors"
+)
+
+// parseTOMLConfig parses a TOML configuration file's data.
+func parseTOMLConfig(file string, data []byte) (*configNode, error) {
+	var v map[string]interface{}
+	if err := toml.Unmarshal(data, &v); err != nil {
+		var pe toml.ParseError
+		if goerrors.As(err, &pe) {
+			pos := newLineCounter(file, data).pos(pe.Position.Start)
+			msg := pe.Message
+			if msg == "" {
+				msg = tomlErrorPrefixRegexp.ReplaceAllString(pe.Error(), "")
+			}
+			return nil, errors.ErrorfFrom(
+				goerrors.New(msg), "%v: invalid TOML", pos,
+			)
+		}
+		return nil, errors.Errorf1From(err, "%v: invalid TOML", file)
+	}
+	// the toml package does not report the positions of keys, so they
+	// are scanned separately.
+	s := tomlScanner{
+		data:      data,
+		lines:     newLineCounter(file, data),
+		positions: make(configPositions),
+		tables:    make(map[string]int),
+	}
+	s.scan()
+	root := ConfigPos{File: file, Line: 1, Column: 1}
+	return s.positions.node(v, nil, root), nil
+}
+
+var tomlErrorPrefixRegexp = regexp.MustCompile(`^toml: line \d+( \(last key "[^"]*"\))?: `)
+
+// node creates the configNode of a decoded TOML value at the path.
+// Values whose positions were not scanned get the position of their
+// parent.
+func (ps configPositions) node(v interface{}, path []string, parent ConfigPos) *configNode {
+	pos, ok := ps[pathKey(path...)]
+	if !ok {
+		pos = parent
+	}
+	n := &configNode{pos: pos}
+	switch v := v.(type) {
+	case bool:
+		n.kind, n.value = boolNode, v
+	case int64, float64:
+		n.kind, n.value = numberNode, fmt.Sprint(v)
+	case string:
+		n.kind, n.value = stringNode, v
+	case time.Time:
+		n.kind, n.value = stringNode, v.Format(time.RFC3339Nano)
+	case []map[string]interface{}:
+		n.kind = arrayNode
+		for i, x := range v {
+			n.items = append(n.items, ps.node(x, appendPath(path, strconv.Itoa(i)), pos))
+		}
+	case []interface{}:
+		n.kind = arrayNode
+		for i, x := range v {
+			n.items = append(n.items, ps.node(x, appendPath(path, strconv.Itoa(i)), pos))
+		}
+	case map[string]interface{}:
+		n.kind = objectNode
+		for k, x := range v {
+			kpath := appendPath(path, k)
+			x := ps.node(x, kpath, pos)
+			n.fields = append(n.fields, configField{key: k, pos: x.pos, value: x})
+		}
+		// decoded in file order so that the first error is reported.
+		sort.Slice(n.fields, func(i, j int) bool {
+			a, b := n.fields[i], n.fields[j]
+			if a.pos.Line != b.pos.Line {
+				return a.pos.Line < b.pos.Line
+			}
+			if a.pos.Column != b.pos.Column {
+				return a.pos.Column < b.pos.Column
+			}
+			return a.key < b.key
+		})
+	}
+	return n
+}
+
+// tomlScanner scans the positions of the keys of a valid TOML document.
+type tomlScanner struct {
+	data      []byte
+	offset    int
+	lines     *lineCounter
+	positions configPositions
+
+	// tables are the numbers of elements of each array of tables by
+	// their pathKeys.
+	tables map[string]int
+}
+
+func (s *tomlScanner) scan() {
+	var table []string
+	for s.skipSpace(true); s.offset < len(s.data); s.skipSpace(true) {
+		if s.data[s.offset] != '[' {
+			if _, ok := s.keyValue(table); !ok {
+				return
+			}
+			continue
+		}
+		pos := s.lines.pos(s.offset)
+		s.offset++
+		array := s.peek('[')
+		if array {
+			s.offset++
+		}
+		s.skipSpace(false)
+		path, ok := s.key(nil)
+		if !ok {
+			return
+		}
+		if array {
+			key := pathKey(path...)
+			i := s.tables[key]
+			s.tables[key] = i + 1
+			path = appendPath(path, strconv.Itoa(i))
+			s.define(path, pos)
+		}
+		table = path
+		s.skipLine()
+	}
+}
+
+// keyValue scans a key/value pair within the table and gets the path of
+// its key.
+func (s *tomlScanner) keyValue(table []string) ([]string, bool) {
+	path, ok := s.key(table)
+	if !ok {
+		return nil, false
+	}
+	s.skipSpace(false)
+	if !s.peek('=') {
+		return nil, false
+	}
+	s.offset++
+	s.skipSpace(false)
+	return path, s.value(path)
+}
+
+// key scans a dotted key and appends its parts to the path.  Parts
+// that name arrays of tables refer to their last tables.
+func (s *tomlScanner) key(path []string) ([]string, bool) {
+	for {
+		pos := s.lines.pos(s.offset)
+		part, ok := s.keyPart()
+		if !ok {
+			return nil, false
+		}
+		path = appendPath(path, part)
+		s.define(path, pos)
+		s.skipSpace(false)
+		if !s.peek('.') {
+			return path, true
+		}
+		if n := s.tables[pathKey(path...)]; n > 0 {
+			path = appendPath(path, strconv.Itoa(n-1))
+		}
+		s.offset++
+		s.skipSpace(false)
+	}
+}
+
+func (s *tomlScanner) keyPart() (string, bool) {
+	if s.offset >= len(s.data) {
+		return "", false
+	}
+	switch s.data[s.offset] {
+	case '"', '\'':
+		start := s.offset
+		if !s.skipString() {
+			return "", false
+		}
+		raw := string(s.data[start:s.offset])
+		if raw[0] == '\'' {
+			return raw[1 : len(raw)-1], true
+		}
+		part, err := strconv.Unquote(raw)
+		return part, err == nil
+	}
+	start := s.offset
+	for s.offset < len(s.data) && isTOMLBareKeyByte(s.data[s.offset]) {
+		s.offset++
+	}
+	return string(s.data[start:s.offset]), s.offset > start
+}
+
+func isTOMLBareKeyByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' ||
+		b >= '0' && b <= '9' || b == '_' || b == '-'
+}
+
+// value scans the value at the path.
+func (s *tomlScanner) value(path []string) bool {
+	if s.offset >= len(s.data) {
+		return false
+	}
+	switch s.data[s.offset] {
+	case '"', '\'':
+		return s.skipString()
+	case '{':
+		s.offset++
+		for {
+			s.skipSpace(false)
+			if s.peek('}') {
+				s.offset++
+				return true
+			}
+			if _, ok := s.keyValue(path); !ok {
+				return false
+			}
+			s.skipSpace(false)
+			if s.peek(',') {
+				s.offset++
+			}
+		}
+	case '[':
+		s.offset++
+		for i := 0; ; i++ {
+			s.skipSpace(true)
+			if s.peek(']') {
+				s.offset++
+				return true
+			}
+			ipath := appendPath(path, strconv.Itoa(i))
+			s.define(ipath, s.lines.pos(s.offset))
+			if !s.value(ipath) {
+				return false
+			}
+			s.skipSpace(true)
+			if s.peek(',') {
+				s.offset++
+			}
+		}
+	}
+	// numbers, booleans and dates:
+	start := s.offset
+	for s.offset < len(s.data) && strings.IndexByte(",]}#\r\n", s.data[s.offset]) == -1 {
+		s.offset++
+	}
+	return s.offset > start
+}
+
+// skipString skips a basic, literal or multi-line string.
+func (s *tomlScanner) skipString() bool {
+	quote := s.data[s.offset]
+	delim := []byte{quote}
+	if s.offset+2 < len(s.data) && s.data[s.offset+1] == quote && s.data[s.offset+2] == quote {
+		delim = []byte{quote, quote, quote}
+	}
+	s.offset += len(delim)
+	for s.offset < len(s.data) {
+		switch {
+		case quote == '"' && s.data[s.offset] == '\\':
+			s.offset += 2
+		case s.hasPrefix(delim):
+			s.offset += len(delim)
+			// multi-line strings can end with up to two more
+			// quotes.
+			for len(delim) == 3 && s.peek(quote) {
+				s.offset++
+			}
+			return true
+		default:
+			s.offset++
+		}
+	}
+	return false
+}
+
+// skipSpace skips whitespace and comments and, if newlines is true,
+// newlines.
+func (s *tomlScanner) skipSpace(newlines bool) {
+	for s.offset < len(s.data) {
+		switch s.data[s.offset] {
+		case ' ', '\t':
+		case '\r', '\n':
+			if !newlines {
+				return
+			}
+		case '#':
+			for s.offset < len(s.data) && s.data[s.offset] != '\n' {
+				s.offset++
+			}
+			continue
+		default:
+			return
+		}
+		s.offset++
+	}
+}
+
+// skipLine skips the rest of the line.
+func (s *tomlScanner) skipLine() {
+	for s.offset < len(s.data) && s.data[s.offset] != '\n' {
+		s.offset++
+	}
+}
+
+func (s *tomlScanner) peek(b byte) bool {
+	return s.offset < len(s.data) && s.data[s.offset] == b
+}
+
+func (s *tomlScanner) hasPrefix(p []byte) bool {
+	return len(s.data)-s.offset >= len(p) && string(s.data[s.offset:s.offset+len(p)]) == string(p)
+}
+
+// define records the position of the path's first definition.
+func (s *tomlScanner) define(path []string, pos ConfigPos) {
+	key := pathKey(path...)
+	if _, ok := s.positions[key]; !ok {
+		s.positions[key] = pos
+	}
+}
@@ -1,12 +1,20 @@
 package sqlmodelgen
 
 import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"hash/fnv"
 	"io"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/skillian/expr/errors"
 	"github.com/skillian/expr/stream/sqlstream/sqltypes"
+	"github.com/skillian/sqlmodel/config"
 	"github.com/xuri/excelize/v2"
 )
 
@@ -14,6 +22,7 @@ var (
 	WVAceModelContext interface {
 		ModelContext
 		ModelWriter
+		ModelSplitter
 	} = wvAceModelContext{}
 )
 
@@ -33,63 +42,139 @@ func (wvAceModelContext) WriteModel(w io.Writer, c *Config) (err error) {
 	if len(c.Databases) == 0 {
 		return errors.Errorf("at least one database is required")
 	}
-	if len(c.Databases) > 1 {
-		return errors.Errorf("ACE files can only define one database")
+	return writeWVAceWorkbook(w, c, c.Databases)
+}
+
+// SplitModel writes each database into its own workbook.
+func (wvAceModelContext) SplitModel(c *Config) ([]ModelFile, error) {
+	mfs := make([]ModelFile, len(c.Databases))
+	for i, db := range c.Databases {
+		dbs := c.Databases[i : i+1]
+		name := db.ModelName
+		if name == "" {
+			name = "Model"
+		}
+		mfs[i] = ModelFile{
+			Path: name + ".xlsx",
+			Write: func(w io.Writer) error {
+				return writeWVAceWorkbook(w, c, dbs)
+			},
+		}
+	}
+	return mfs, nil
+}
+
+// writeWVAceWorkbook writes a workbook of the classes of the tables in
+// the databases, dbs.
+func writeWVAceWorkbook(w io.Writer, c *Config, dbs []*Database) (err error) {
+	var prefixed bool
+	switch c.WVAceClassPrefix {
+	case "":
+		prefixed = len(dbs) > 1
+	case "database":
+		prefixed = true
+	}
+	tbls := make([]*Table, 0, 64)
+	classNames := make(map[*Table]string, 64)
+	for _, db := range dbs {
+		for _, sch := range db.Schemas {
+			for _, tbl := range sch.Tables {
+				tbls = append(tbls, tbl)
+				classNames[tbl] = wvAceClassName(tbl, prefixed)
+			}
+		}
+	}
+	sort.Slice(tbls, func(i, j int) bool {
+		return classNames[tbls[i]] < classNames[tbls[j]]
+	})
+	names := make([]string, len(tbls))
+	for i, tbl := range tbls {
+		names[i] = classNames[tbl]
+	}
+	sheetNames, err := wvAceSheetNames(names)
+	if err != nil {
+		return err
 	}
-	db := c.Databases[0]
 	f := excelize.NewFile()
 	s := wvAceClassSheet{}
-	for _, sch := range db.Schemas {
-		for _, tbl := range sch.Tables {
-			wvClassName := wvAceClassName(tbl)
-			createWVAceClassSheet(f, wvClassName)
-			s.Filters = "All " + wvClassName
-			s.Views = wvClassName
-			s.Sections = wvClassName
-			for i, col := range tbl.Columns {
-				s.DisplayName = col.ModelName
-				s.DataType, s.LengthPrecision, err = wvAceType(col.Type)
-				if err != nil {
-					return errors.Errorf1From(
-						err, "error getting WorkView "+
-							"ACE data type of "+
-							"column %v",
-						col,
-					)
-				}
-				s.RelatedClass = ""
-				if col.FK != nil {
-					fkCol := col.FK.Column
-					if !fkCol.PK {
-						return errors.Errorf(
-							"column %[1]v of table %[2]v "+
-								"references column %[3]v of %[4]v "+
-								"but %[3]v of %[4]v "+
-								"is not a primary key",
-							col, tbl, fkCol, fkCol.Table,
-						)
-					}
-					s.RelatedClass = wvAceClassName(fkCol.Table)
-					s.DataType = "Relation"
-				}
-				// TODO: Column documentation
-				// TODO: Column datasets?
-				// TODO: Column default values?
-				s.PrimaryAttribute = col.PK
-				if err = s.writeRow(f, wvClassName, i+2); err != nil {
-					return errors.Errorf2From(
-						err, "error while writing "+
-							"row for column %v "+
-							"of table %v",
-						col, tbl,
+	scales := make([]wvAceDecimalScale, 0, 8)
+	for i, tbl := range tbls {
+		wvClassName := names[i]
+		sheet := sheetNames[i]
+		createWVAceClassSheet(f, sheet)
+		for i, col := range tbl.Columns {
+			s.DisplayName = col.ModelName
+			s.Filters = wvAceList(
+				col.WorkView.Filters, tbl.WorkView.Filters,
+				"All "+wvClassName,
+			)
+			s.Views = wvAceList(
+				col.WorkView.Views, tbl.WorkView.Views,
+				wvClassName,
+			)
+			s.Sections = wvAceList(
+				col.WorkView.Sections, tbl.WorkView.Sections,
+				wvClassName,
+			)
+			s.Description = col.WorkView.Description
+			s.DataSet = strings.Join(col.WorkView.DataSet, wvAceListSeparator)
+			s.DefaultValue = col.WorkView.DefaultValue
+			s.Index = col.WorkView.Index
+			s.DataType, s.LengthPrecision, err = wvAceType(col.Type)
+			if err != nil {
+				return errors.Errorf1From(
+					err, "error getting WorkView "+
+						"ACE data type of "+
+						"column %v",
+					col,
+				)
+			}
+			if scale := wvAceScaleOf(col.Type); scale != 0 {
+				scales = append(scales, wvAceDecimalScale{
+					Class:     wvClassName,
+					Attribute: col.ModelName,
+					Scale:     scale,
+				})
+			}
+			s.RelatedClass = ""
+			if col.FK != nil {
+				fkCol := col.FK.Column
+				if !fkCol.PK {
+					return errors.Errorf(
+						"column %[1]v of table %[2]v "+
+							"references column %[3]v of %[4]v "+
+							"but %[3]v of %[4]v "+
+							"is not a primary key",
+						col, tbl, fkCol, fkCol.Table,
 					)
 				}
+				s.RelatedClass = wvAceClassName(fkCol.Table, prefixed)
+				s.DataType = "Relation"
+			}
+			s.PrimaryAttribute = col.PK
+			if err = s.writeRow(f, sheet, i+2); err != nil {
+				return errors.Errorf2From(
+					err, "error while writing "+
+						"row for column %v "+
+						"of table %v",
+					col, tbl,
+				)
 			}
 		}
 	}
+	if err = writeWVAceClassNamesSheet(f, names, sheetNames); err != nil {
+		return err
+	}
+	if err = writeWVAceDecimalScalesSheet(f, scales); err != nil {
+		return err
+	}
 	// delete default sheet
 	f.DeleteSheet(f.GetSheetName(0))
-	if _, err = f.WriteTo(w); err != nil {
+	var buf bytes.Buffer
+	if _, err = f.WriteTo(&buf); err != nil {
+		return errors.Errorf0From(err, "failed to write Excel file")
+	}
+	if err = writeSortedZip(w, buf.Bytes()); err != nil {
 		return errors.Errorf1From(
 			err, "failed to write Excel file to %v", w,
 		)
@@ -97,6 +182,43 @@ func (wvAceModelContext) WriteModel(w io.Writer, c *Config) (err error) {
 	return nil
 }
 
+// writeSortedZip rewrites the zip archive in data into w with its files
+// sorted by name.  excelize writes the files of a workbook in a random
+// order, so the same workbook would otherwise be written differently
+// every time.
+func writeSortedZip(w io.Writer, data []byte) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	files := append([]*zip.File(nil), zr.File...)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	zw := zip.NewWriter(w)
+	for _, zf := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:   zf.Name,
+			Method: zf.Method,
+		})
+		if err != nil {
+			return err
+		}
+		fr, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(fw, fr)
+		if err2 := fr.Close(); err == nil {
+			err = err2
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
 var wvAceClassSheetHeaders = []string{
 	"Display Name",
 	"Data Type",
@@ -112,6 +234,54 @@ var wvAceClassSheetHeaders = []string{
 	"Primary Attribute",
 }
 
+// wvAceListSeparator separates the names in an ACE cell that holds
+// multiple names (e.g. Filters or Data Set values).
+const wvAceListSeparator = ", "
+
+// wvAceList joins a column's list of names or, if it is empty, its
+// table's list or, if that is empty too, returns def.
+func wvAceList(col, tbl []string, def string) string {
+	switch {
+	case len(col) > 0:
+		return strings.Join(col, wvAceListSeparator)
+	case len(tbl) > 0:
+		return strings.Join(tbl, wvAceListSeparator)
+	}
+	return def
+}
+
+// checkWVAceLists checks that none of the names in the lists of a
+// WorkView table's or column's metadata, by their field names, contain
+// the wvAceListSeparator's comma so that the lists can be split again.
+// The error is at the path of the first offending name.
+func checkWVAceLists(lists map[string][]string) error {
+	for _, field := range sortedKeys(lists) {
+		for i, name := range lists[field] {
+			if strings.Contains(name, strings.TrimSpace(wvAceListSeparator)) {
+				return configErrorAt(errors.Errorf2(
+					"WorkView %s value %q cannot contain "+
+						"commas",
+					field, name,
+				), "WorkView", field, strconv.Itoa(i))
+			}
+		}
+	}
+	return nil
+}
+
+// splitWVAceList is the inverse of wvAceList.  It returns nil if s is
+// def.
+func splitWVAceList(s, def string) []string {
+	if s == "" || s == def {
+		return nil
+	}
+	l := strings.Split(s, strings.TrimSpace(wvAceListSeparator))
+	for i, x := range l {
+		l[i] = strings.TrimSpace(x)
+	}
+	return l
+}
+
 func createWVAceClassSheet(f *excelize.File, name string) {
 	_ = f.NewSheet(name)
 	for i, h := range wvAceClassSheetHeaders {
@@ -119,12 +289,228 @@ func createWVAceClassSheet(f *excelize.File, name string) {
 	}
 }
 
+// excelColumn gets the letters of the zero-based column index:  A..Z,
+// AA..AZ, BA..ZZ, AAA, etc.
 func excelColumn(index int) string {
-	// Add AA..ZZ, etc. support if ever needed
-	if index >= 26 {
-		panic("only 26 columns supported")
+	var bs [8]byte
+	i := len(bs)
+	for index++; index > 0; index = (index - 1) / 26 {
+		i--
+		bs[i] = byte('A' + (index-1)%26)
+	}
+	return string(bs[i:])
+}
+
+const (
+	// excelMaxSheetNameLen is the maximum length of an Excel sheet
+	// name.
+	excelMaxSheetNameLen = 31
+
+	// excelSheetNameForbidden are the characters that cannot appear
+	// in sheet names.
+	excelSheetNameForbidden = `:\/?*[]`
+
+	// wvAceClassNamesSheet is the name of the sheet that maps sheet
+	// names back to the class names that had to be shortened.
+	wvAceClassNamesSheet = "Class Names"
+
+	// wvAceDecimalScalesSheet is the name of the sheet that holds the
+	// scales of decimal attributes, which ACE class sheets only have
+	// the precision of.
+	wvAceDecimalScalesSheet = "Decimal Scales"
+)
+
+// wvAceSheetNames gets the sheet names of the classes.  Class names
+// that are too long to be sheet names or that contain characters that
+// sheet names can't are shortened deterministically:  Forbidden
+// characters are replaced with underscores and long names are truncated
+// and suffixed with a hash of the whole class name.  Sheet names are
+// compared case-insensitively, like Excel does, and any collisions
+// that remain get a numeric suffix in the order of the classes.
+func wvAceSheetNames(classNames []string) ([]string, error) {
+	used := make(map[string]struct{}, len(classNames)+3)
+	used[strings.ToLower(wvAceClassNamesSheet)] = struct{}{}
+	used[strings.ToLower(wvAceDecimalScalesSheet)] = struct{}{}
+	used["history"] = struct{}{}
+	sheetNames := make([]string, len(classNames))
+	for i, className := range classNames {
+		if className == "" {
+			return nil, errors.Errorf0("class names cannot be empty")
+		}
+		name := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(excelSheetNameForbidden, r) {
+				return '_'
+			}
+			return r
+		}, className)
+		name = strings.Trim(name, "'")
+		if len(name) > excelMaxSheetNameLen {
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(className))
+			suffix := fmt.Sprintf("~%08x", h.Sum32())
+			name = truncateUTF8(name, excelMaxSheetNameLen-len(suffix)) + suffix
+		}
+		base := name
+		for n := 2; ; n++ {
+			if _, ok := used[strings.ToLower(name)]; !ok {
+				break
+			}
+			suffix := "~" + strconv.Itoa(n)
+			name = truncateUTF8(base, excelMaxSheetNameLen-len(suffix)) + suffix
+		}
+		used[strings.ToLower(name)] = struct{}{}
+		sheetNames[i] = name
+	}
+	return sheetNames, nil
+}
+
+// truncateUTF8 truncates s to at most n bytes without splitting a
+// UTF-8 sequence.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// writeWVAceClassNamesSheet writes the sheet that maps the sheet names
+// back to their class names if any of them differ.
+func writeWVAceClassNamesSheet(f *excelize.File, classNames, sheetNames []string) error {
+	row := 1
+	for i, className := range classNames {
+		if sheetNames[i] == className {
+			continue
+		}
+		if row == 1 {
+			_ = f.NewSheet(wvAceClassNamesSheet)
+			for j, h := range wvAceClassNamesSheetHeaders {
+				if err := f.SetCellStr(wvAceClassNamesSheet, excelColumn(j)+"1", h); err != nil {
+					return err
+				}
+			}
+		}
+		row++
+		ixstr := strconv.Itoa(row)
+		if err := f.SetCellStr(wvAceClassNamesSheet, "A"+ixstr, sheetNames[i]); err != nil {
+			return err
+		}
+		if err := f.SetCellStr(wvAceClassNamesSheet, "B"+ixstr, className); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var wvAceClassNamesSheetHeaders = []string{
+	"Sheet Name",
+	"Class Name",
+}
+
+// readWVAceClassNamesSheet reads the mapping of sheet names to class
+// names from the workbook if it has one.
+func readWVAceClassNamesSheet(f *excelize.File) (map[string]string, error) {
+	m := make(map[string]string)
+	if f.GetSheetIndex(wvAceClassNamesSheet) == -1 {
+		return m, nil
+	}
+	rows, err := f.GetRows(wvAceClassNamesSheet)
+	if err != nil {
+		return nil, errors.Errorf1From(
+			err, "failed to read rows of sheet %q",
+			wvAceClassNamesSheet,
+		)
+	}
+	for i, row := range rows {
+		if i == 0 || len(row) < 2 || row[0] == "" {
+			continue
+		}
+		m[row[0]] = row[1]
 	}
-	return string('A' + rune(index))
+	return m, nil
+}
+
+// wvAceDecimalScale is the scale of a decimal attribute of a class.
+type wvAceDecimalScale struct {
+	Class     string
+	Attribute string
+	Scale     int
+}
+
+// writeWVAceDecimalScalesSheet writes the sheet of the decimal
+// attributes' scales if there are any.
+func writeWVAceDecimalScalesSheet(f *excelize.File, scales []wvAceDecimalScale) error {
+	if len(scales) == 0 {
+		return nil
+	}
+	_ = f.NewSheet(wvAceDecimalScalesSheet)
+	for j, h := range wvAceDecimalScalesSheetHeaders {
+		if err := f.SetCellStr(wvAceDecimalScalesSheet, excelColumn(j)+"1", h); err != nil {
+			return err
+		}
+	}
+	for i, ds := range scales {
+		ixstr := strconv.Itoa(i + 2)
+		if err := f.SetCellStr(wvAceDecimalScalesSheet, "A"+ixstr, ds.Class); err != nil {
+			return err
+		}
+		if err := f.SetCellStr(wvAceDecimalScalesSheet, "B"+ixstr, ds.Attribute); err != nil {
+			return err
+		}
+		if err := f.SetCellInt(wvAceDecimalScalesSheet, "C"+ixstr, ds.Scale); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var wvAceDecimalScalesSheetHeaders = []string{
+	"Class Name",
+	"Attribute",
+	"Scale",
+}
+
+// readWVAceDecimalScalesSheet reads the scales of decimal attributes,
+// by their class and attribute names, from the workbook if it has them.
+func readWVAceDecimalScalesSheet(f *excelize.File) (map[[2]string]int, error) {
+	m := make(map[[2]string]int)
+	if f.GetSheetIndex(wvAceDecimalScalesSheet) == -1 {
+		return m, nil
+	}
+	rows, err := f.GetRows(wvAceDecimalScalesSheet)
+	if err != nil {
+		return nil, errors.Errorf1From(
+			err, "failed to read rows of sheet %q",
+			wvAceDecimalScalesSheet,
+		)
+	}
+	for i, row := range rows {
+		if i == 0 || len(row) < 3 || row[0] == "" {
+			continue
+		}
+		scale, err := strconv.Atoi(strings.TrimSpace(row[2]))
+		if err != nil {
+			return nil, errors.Errorf2From(
+				err, "invalid scale in row %d of sheet %q",
+				i+1, wvAceDecimalScalesSheet,
+			)
+		}
+		m[[2]string{row[0], row[1]}] = scale
+	}
+	return m, nil
+}
+
+// wvAceScaleOf gets the scale of t if it is a decimal.
+func wvAceScaleOf(t sqltypes.Type) (scale int) {
+	_ = sqltypes.IterInners(t, func(x sqltypes.Type) error {
+		if dt, ok := x.(sqltypes.DecimalType); ok {
+			scale = dt.Scale
+		}
+		return io.EOF
+	})
+	return
 }
 
 func wvAceType(t sqltypes.Type) (dataType string, lengthPrecision int, err error) {
@@ -133,32 +519,31 @@ func wvAceType(t sqltypes.Type) (dataType string, lengthPrecision int, err error
 		return wvAceType(t[0])
 	case sqltypes.BoolType:
 		return "Boolean", 0, nil
-	case sqltypes.IntType:
+	case sqltypes.IntType, UintType:
 		return "Integer", 0, nil
 	case sqltypes.FloatType:
 		return "Floating Point", 0, nil
 	case sqltypes.DecimalType:
 		return "Decimal", t.Prec, nil
 	case sqltypes.StringType:
-		if t.Var {
+		// Alphanumeric attributes require a length.
+		if t.Var || t.Length == 0 || t.Length >= 256 {
 			return "Text", 0, nil
 		}
-		if t.Length < 256 {
-			return "Alphanumeric", t.Length, nil
-		}
-		return "Text", 0, nil
+		return "Alphanumeric", t.Length, nil
 	case sqltypes.TimeType:
 		if t.Prec >= 24*time.Hour {
 			return "Date", 0, nil
 		}
 		return "Date/Time", 0, nil
+	case ZonedTimeType:
+		return "Date/Time", 0, nil
+	case DurationType:
+		return "Floating Point", 0, nil
+	case GUIDType:
+		return "Alphanumeric", 36, nil
 	case sqltypes.BytesType:
-		if t.Var {
-			return "Text", 0, nil
-		}
-		if t.Length < 256 {
-			return "Alphanumeric", 0, nil
-		}
+		// The length of bytes' text form depends on its encoding.
 		return "Text", 0, nil
 	}
 	return "", 0, errors.Errorf1(
@@ -167,7 +552,13 @@ func wvAceType(t sqltypes.Type) (dataType string, lengthPrecision int, err error
 	)
 }
 
-func wvAceClassName(tbl *Table) string {
+// wvAceClassName gets the name of the table's WorkView class.  If
+// prefixed is true, the name starts with the table's database's name so
+// that classes from multiple databases can share a workbook.
+func wvAceClassName(tbl *Table, prefixed bool) string {
+	if prefixed {
+		return tbl.Schema.Database.ModelName + tbl.Schema.ModelName + tbl.ModelName
+	}
 	return tbl.Schema.ModelName + tbl.ModelName
 }
 
@@ -188,47 +579,266 @@ type wvAceClassSheet struct {
 
 func (s *wvAceClassSheet) writeRow(f *excelize.File, sheet string, index int) (err error) {
 	ixstr := strconv.Itoa(index)
-	if err = f.SetCellStr(sheet, "A"+ixstr, s.DisplayName); err != nil {
-		return err
+	cells := [...]interface{}{
+		s.DisplayName,
+		s.DataType,
+		s.RelatedClass,
+		s.LengthPrecision,
+		s.Description,
+		s.DataSet,
+		s.DefaultValue,
+		s.Index,
+		s.Filters,
+		s.Views,
+		s.Sections,
+		s.PrimaryAttribute,
 	}
-	if err = f.SetCellStr(sheet, "B"+ixstr, s.DataType); err != nil {
-		return err
-	}
-	if err = f.SetCellStr(sheet, "C"+ixstr, s.RelatedClass); err != nil {
-		return err
-	}
-	if s.LengthPrecision == 0 {
-		if err = f.SetCellStr(sheet, "D"+ixstr, ""); err != nil {
-			return err
+	for i, v := range cells {
+		axis := excelColumn(i) + ixstr
+		switch v := v.(type) {
+		case string:
+			err = f.SetCellStr(sheet, axis, v)
+		case int:
+			if v == 0 {
+				err = f.SetCellStr(sheet, axis, "")
+			} else {
+				err = f.SetCellInt(sheet, axis, v)
+			}
+		case bool:
+			err = f.SetCellBool(sheet, axis, v)
 		}
-	} else {
-		if err = f.SetCellInt(sheet, "D"+ixstr, s.LengthPrecision); err != nil {
+		if err != nil {
 			return err
 		}
 	}
-	if err = f.SetCellStr(sheet, "E"+ixstr, s.Description); err != nil {
-		return err
+	return nil
+}
+
+// ReadWVAceConfig reads a WorkView ACE workbook such as the ones written
+// by WVAceModelContext into a configuration with a single database and
+// schema.  Each class sheet becomes a table whose columns are the
+// sheet's attributes:  "Relation" attributes become foreign keys to
+// their Related Class's primary attribute and the other data types and
+// lengths are translated back into sqltypes, with the scales of decimals
+// from the Decimal Scales sheet.  WorkView attributes do not record
+// nullability, so no column is nullable.
+func ReadWVAceConfig(r io.Reader, database, schema string) (*config.Config, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, errors.Errorf0From(
+			err, "failed to open WorkView ACE workbook",
+		)
 	}
-	if err = f.SetCellStr(sheet, "F"+ixstr, s.DataSet); err != nil {
-		return err
+	classNames, err := readWVAceClassNamesSheet(f)
+	if err != nil {
+		return nil, err
 	}
-	if err = f.SetCellStr(sheet, "G"+ixstr, s.DefaultValue); err != nil {
-		return err
+	scales, err := readWVAceDecimalScalesSheet(f)
+	if err != nil {
+		return nil, err
 	}
-	if err = f.SetCellStr(sheet, "H"+ixstr, s.Index); err != nil {
-		return err
+	sch := config.Schema{Tables: make(map[string]config.Table)}
+	type relation struct{ table, column, class string }
+	relations := make([]relation, 0, 8)
+	pks := make(map[string][]string)
+	for _, sheet := range f.GetSheetList() {
+		if sheet == wvAceClassNamesSheet || sheet == wvAceDecimalScalesSheet {
+			continue
+		}
+		class := sheet
+		if x, ok := classNames[sheet]; ok {
+			class = x
+		}
+		rows, err := f.GetRows(sheet)
+		if err != nil {
+			return nil, errors.Errorf1From(
+				err, "failed to read rows of sheet %q", sheet,
+			)
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		hs := make(map[string]int, len(rows[0]))
+		for i, h := range rows[0] {
+			hs[strings.TrimSpace(h)] = i
+		}
+		if _, ok := hs["Display Name"]; !ok {
+			logger.Warn1("skipping sheet %q without a Display Name column", sheet)
+			continue
+		}
+		if _, ok := hs["Data Type"]; !ok {
+			logger.Warn1("skipping sheet %q without a Data Type column", sheet)
+			continue
+		}
+		tbl := config.Table{Columns: make(map[string]config.Column, len(rows)-1)}
+		for i, row := range rows[1:] {
+			s, err := readWVAceClassSheetRow(hs, row)
+			if err != nil {
+				return nil, errors.Errorf2From(
+					err, "invalid row %d of sheet %q",
+					i+2, sheet,
+				)
+			}
+			if s.DisplayName == "" {
+				continue
+			}
+			if _, ok := tbl.Columns[s.DisplayName]; ok {
+				return nil, errors.Errorf2(
+					"attribute %q of class %q is defined "+
+						"more than once",
+					s.DisplayName, class,
+				)
+			}
+			col := config.Column{
+				PK: s.PrimaryAttribute,
+				WorkView: config.WorkViewColumn{
+					Description:  s.Description,
+					Filters:      splitWVAceList(s.Filters, "All "+class),
+					Views:        splitWVAceList(s.Views, class),
+					Sections:     splitWVAceList(s.Sections, class),
+					DataSet:      splitWVAceList(s.DataSet, ""),
+					DefaultValue: s.DefaultValue,
+					Index:        s.Index,
+				},
+			}
+			if s.DataType == "Relation" {
+				if s.RelatedClass == "" {
+					return nil, errors.Errorf2(
+						"relation %q of class %q has no "+
+							"Related Class",
+						s.DisplayName, class,
+					)
+				}
+				relations = append(relations, relation{
+					table:  class,
+					column: s.DisplayName,
+					class:  s.RelatedClass,
+				})
+			} else if col.Type, err = wvAceSQLType(
+				s.DataType, s.LengthPrecision,
+				scales[[2]string{class, s.DisplayName}],
+			); err != nil {
+				return nil, errors.Errorf2From(
+					err, "invalid data type of attribute %q "+
+						"of class %q",
+					s.DisplayName, class,
+				)
+			}
+			if col.PK {
+				pks[class] = append(pks[class], s.DisplayName)
+			}
+			tbl.Columns[s.DisplayName] = col
+		}
+		sch.Tables[class] = tbl
 	}
-	if err = f.SetCellStr(sheet, "I"+ixstr, s.Filters); err != nil {
-		return err
+	for _, rel := range relations {
+		if _, ok := sch.Tables[rel.class]; !ok {
+			return nil, errors.Errorf3(
+				"attribute %q of class %q relates to "+
+					"undefined class %q",
+				rel.column, rel.table, rel.class,
+			)
+		}
+		pk := pks[rel.class]
+		if len(pk) != 1 {
+			return nil, errors.Errorf3(
+				"attribute %q of class %q relates to class "+
+					"%q which must have exactly one "+
+					"primary attribute",
+				rel.column, rel.table, rel.class,
+			)
+		}
+		col := sch.Tables[rel.table].Columns[rel.column]
+		col.FK = rel.class + "." + pk[0]
+		sch.Tables[rel.table].Columns[rel.column] = col
 	}
-	if err = f.SetCellStr(sheet, "J"+ixstr, s.Views); err != nil {
-		return err
+	return &config.Config{
+		Databases: map[string]config.Database{
+			database: {
+				Schemas: map[string]config.Schema{
+					schema: sch,
+				},
+			},
+		},
+	}, nil
+}
+
+// readWVAceClassSheetRow reads a row of a class sheet whose headers'
+// indexes are in hs.
+func readWVAceClassSheetRow(hs map[string]int, row []string) (s wvAceClassSheet, err error) {
+	cell := func(header string) string {
+		i, ok := hs[header]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
 	}
-	if err = f.SetCellStr(sheet, "K"+ixstr, s.Sections); err != nil {
-		return err
+	s.DisplayName = cell("Display Name")
+	s.DataType = cell("Data Type")
+	s.RelatedClass = cell("Related Class")
+	if x := cell("Length / Precision"); x != "" {
+		if s.LengthPrecision, err = strconv.Atoi(x); err != nil {
+			return s, errors.Errorf1From(
+				err, "failed to parse %q as a length or "+
+					"precision", x,
+			)
+		}
 	}
-	if err = f.SetCellBool(sheet, "L"+ixstr, s.PrimaryAttribute); err != nil {
-		return err
+	s.Description = cell("Description")
+	s.DataSet = cell("Data Set")
+	s.DefaultValue = cell("Default Value")
+	s.Index = cell("Index")
+	s.Filters = cell("Filters")
+	s.Views = cell("Views")
+	s.Sections = cell("Sections")
+	if x := cell("Primary Attribute"); x != "" {
+		if s.PrimaryAttribute, err = strconv.ParseBool(x); err != nil {
+			return s, errors.Errorf1From(
+				err, "failed to parse %q as a primary "+
+					"attribute flag", x,
+			)
+		}
 	}
-	return nil
+	return s, nil
+}
+
+// wvAceSQLType translates a WorkView data type back into a sqltypes
+// definition.  It is the inverse of wvAceType.  The scale is only used
+// by decimals.
+func wvAceSQLType(dataType string, lengthPrecision, scale int) (string, error) {
+	switch dataType {
+	case "Boolean":
+		return "bool", nil
+	case "Integer":
+		return "int(64)", nil
+	case "Floating Point":
+		return "float(53)", nil
+	case "Decimal":
+		var params []string
+		if scale != 0 {
+			params = append(params, fmt.Sprintf("scale: %d", scale))
+		}
+		if lengthPrecision != 0 {
+			params = append(params, fmt.Sprintf("prec: %d", lengthPrecision))
+		}
+		return "decimal(" + strings.Join(params, ", ") + ")", nil
+	case "Currency":
+		return "decimal(scale: 4, prec: 19)", nil
+	case "Alphanumeric", "Encrypted Alphanumeric":
+		if lengthPrecision == 0 {
+			return "", errors.Errorf1(
+				"%v requires a length", dataType,
+			)
+		}
+		return fmt.Sprintf("string(length: %d)", lengthPrecision), nil
+	case "Text", "Formatted Text":
+		return "string(var: true)", nil
+	case "Date":
+		return "date(prec: 24h)", nil
+	case "Date/Time":
+		return "date(prec: 1s)", nil
+	}
+	return "", errors.Errorf1(
+		"unknown WorkView data type: %q", dataType,
+	)
 }
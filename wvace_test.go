@@ -0,0 +1,232 @@
+package sqlmodelgen
+
+import (
+	"bytes"
+	goerrors "errors"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/skillian/sqlmodel/config"
+)
+
+// writeReadWVAce writes the columns into a workbook and reads the
+// workbook's configuration back.
+func writeReadWVAce(t *testing.T, columns map[string]config.Column) (*config.Config, error) {
+	t.Helper()
+	c, err := NewConfig(&config.Config{
+		Databases: map[string]config.Database{
+			"DB": {Schemas: map[string]config.Schema{
+				"dbo": {Tables: map[string]config.Table{
+					"Party": {Columns: columns},
+				}},
+			}},
+		},
+	}, WVAceModelContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WVAceModelContext.WriteModel(&buf, c); err != nil {
+		return nil, err
+	}
+	return ReadWVAceConfig(&buf, "DB", "dbo")
+}
+
+func TestWVAceRoundTripTypes(t *testing.T) {
+	tests := []struct {
+		name, typ, want string
+	}{
+		{"ID", "int(32)", "int(64)"},
+		{"Flag", "bool", "bool"},
+		{"Code", "string(length: 10)", "string(length: 10)"},
+		{"Fixed", "string()", "string(var: true)"},
+		{"Name", "string(length: 64, var: true)", "string(var: true)"},
+		{"Long", "string(length: 300)", "string(var: true)"},
+		{"Hash", "bytes(length: 16)", "string(var: true)"},
+		{"Blob", "bytes(var: true)", "string(var: true)"},
+		{"GUID", "guid", "string(length: 36)"},
+		{"Born", "date(prec: 24h)", "date(prec: 24h)"},
+		{"Seen", "date(prec: 1s)", "date(prec: 1s)"},
+		{"Nick", "nullable(string(length: 5))", "string(length: 5)"},
+		{"Fee", "decimal(scale: 2, prec: 10)", "decimal(scale: 2, prec: 10)"},
+		{"Rate", "nullable(decimal(scale: 4, prec: 9))", "decimal(scale: 4, prec: 9)"},
+		{"Count", "decimal(prec: 12)", "decimal(prec: 12)"},
+		{"Any", "decimal()", "decimal()"},
+	}
+	columns := make(map[string]config.Column, len(tests))
+	for _, tc := range tests {
+		columns[tc.name] = config.Column{PK: tc.name == "ID", Type: tc.typ}
+	}
+	cfg, err := writeReadWVAce(t, columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cfg.Databases["DB"].Schemas["dbo"].Tables["dboParty"].Columns
+	for _, tc := range tests {
+		if typ := got[tc.name].Type; typ != tc.want {
+			t.Errorf("%s: %s was read back as %q, want %q", tc.name, tc.typ, typ, tc.want)
+		}
+	}
+}
+
+func TestWVAceListsWithCommas(t *testing.T) {
+	tests := []struct {
+		name     string
+		table    config.WorkViewTable
+		column   config.WorkViewColumn
+		wantPath []string
+	}{
+		{
+			name:   "data set",
+			column: config.WorkViewColumn{DataSet: []string{"a", "b, c"}},
+			wantPath: []string{
+				"Databases", "DB", "Schemas", "dbo", "Tables", "Party",
+				"Columns", "Kind", "WorkView", "DataSet", "1",
+			},
+		},
+		{
+			name:   "column filter",
+			column: config.WorkViewColumn{Filters: []string{"a,b"}},
+			wantPath: []string{
+				"Databases", "DB", "Schemas", "dbo", "Tables", "Party",
+				"Columns", "Kind", "WorkView", "Filters", "0",
+			},
+		},
+		{
+			name:  "table section",
+			table: config.WorkViewTable{Sections: []string{"x", "y,"}},
+			wantPath: []string{
+				"Databases", "DB", "Schemas", "dbo", "Tables", "Party",
+				"WorkView", "Sections", "1",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewConfig(&config.Config{
+				Databases: map[string]config.Database{
+					"DB": {Schemas: map[string]config.Schema{
+						"dbo": {Tables: map[string]config.Table{
+							"Party": {
+								Columns: map[string]config.Column{
+									"Kind": {Type: "string(length: 8)", WorkView: tc.column},
+								},
+								WorkView: tc.table,
+							},
+						}},
+					}},
+				},
+			}, WVAceModelContext)
+			var ce *ConfigError
+			if !goerrors.As(err, &ce) {
+				t.Fatalf("got %v, want a *ConfigError", err)
+			}
+			if !reflect.DeepEqual(ce.Path, tc.wantPath) {
+				t.Errorf("got path %q, want %q", ce.Path, tc.wantPath)
+			}
+			if msg := firstLine(err); !strings.Contains(msg, "cannot contain commas") {
+				t.Errorf("unexpected error: %s", msg)
+			}
+		})
+	}
+}
+
+func TestExcelColumn(t *testing.T) {
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{0, "A"},
+		{25, "Z"},
+		{26, "AA"},
+		{51, "AZ"},
+		{52, "BA"},
+		{701, "ZZ"},
+		{702, "AAA"},
+		{16383, "XFD"},
+	}
+	for _, tc := range tests {
+		if got := excelColumn(tc.index); got != tc.want {
+			t.Errorf("excelColumn(%d) = %q, want %q", tc.index, got, tc.want)
+		}
+	}
+}
+
+func TestWVAceSheetNames(t *testing.T) {
+	long := "dboAVeryLongTableNameThatExceedsExcelsLimit"
+	tests := []struct {
+		name       string
+		classNames []string
+		want       []string
+	}{
+		{
+			"unchanged",
+			[]string{"dboParty", "dboDocket"},
+			[]string{"dboParty", "dboDocket"},
+		},
+		{
+			"forbidden characters",
+			[]string{"a:b/c?d*e[f]g\\h", "'quoted'"},
+			[]string{"a_b_c_d_e_f_g_h", "quoted"},
+		},
+		{
+			"truncated",
+			[]string{long},
+			[]string{long[:22] + "~" + fnvHex(long)},
+		},
+		{
+			"case-insensitive collisions",
+			[]string{"dboParty", "DBOPARTY", "dboparty"},
+			[]string{"dboParty", "DBOPARTY~2", "dboparty~3"},
+		},
+		{
+			"reserved names",
+			[]string{"History", "Class Names", "decimal scales"},
+			[]string{"History~2", "Class Names~2", "decimal scales~2"},
+		},
+		{
+			"collisions after replacement",
+			[]string{"a:b", "a/b", "a_b"},
+			[]string{"a_b", "a_b~2", "a_b~3"},
+		},
+		{
+			"truncated collisions",
+			[]string{strings.Repeat("x", 31), strings.Repeat("X", 31)},
+			[]string{strings.Repeat("x", 31), strings.Repeat("X", 29) + "~2"},
+		},
+		{
+			"multibyte truncation",
+			[]string{strings.Repeat("é", 20)},
+			[]string{strings.Repeat("é", 11) + "~" + fnvHex(strings.Repeat("é", 20))},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := wvAceSheetNames(tc.classNames)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+			for _, name := range got {
+				if len(name) > excelMaxSheetNameLen {
+					t.Errorf("%q is longer than %d bytes", name, excelMaxSheetNameLen)
+				}
+			}
+		})
+	}
+	if _, err := wvAceSheetNames([]string{""}); err == nil {
+		t.Error("expected an error for an empty class name")
+	}
+}
+
+// fnvHex gets the hash suffix of the truncated sheet name of a class.
+func fnvHex(className string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(className))
+	return fmt.Sprintf("%08x", h.Sum32())
+}